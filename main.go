@@ -2,11 +2,16 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log"
+	"math"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	_ "poc-integracoes-onm/docs" // Importa a documentação do Swagger
 	"poc-integracoes-onm/models"
@@ -25,10 +30,30 @@ var (
 	metaRedirectURI string
 	metaState       string
 
+	metaWebhookVerifyToken string
+
 	googleClientID     string
 	googleClientSecret string
 	googleRedirectURI  string
 	googleState        string
+
+	// Armazenamento dos eventos de checkout recebidos pelos webhooks
+	eventStore = services.NewEventStore()
+
+	// Relatórios assíncronos de insights enviados ao Meta Ads
+	metaReportJobs = services.NewMetaReportJobStore()
+
+	// Tokens das páginas usados para ler os leads recebidos pelo webhook do Meta
+	metaPageTokens = services.NewMetaPageTokenStore()
+
+	// Histórico das alterações feitas em campanhas e conjuntos de anúncios do Meta Ads
+	metaAuditLog = services.NewMetaAuditLog()
+
+	// Públicos personalizados do Meta Ads sincronizados com os eventos de checkout
+	metaAudiences = services.NewMetaAudienceStore()
+
+	// Intervalo da sincronização agendada dos públicos personalizados (0 desativa)
+	metaAudienceSyncInterval = time.Hour
 )
 
 // @title API de Webhooks e Integrações
@@ -47,6 +72,15 @@ func init() {
 	metaAppSecret = os.Getenv("META_APP_SECRET")
 	metaRedirectURI = os.Getenv("META_REDIRECT_URI")
 	metaState = os.Getenv("META_STATE")
+	metaWebhookVerifyToken = os.Getenv("META_WEBHOOK_VERIFY_TOKEN")
+	if value := os.Getenv("META_AUDIENCE_SYNC_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil {
+			log.Printf("Warning: META_AUDIENCE_SYNC_INTERVAL inválido (%s), usando %s", value, metaAudienceSyncInterval)
+		} else {
+			metaAudienceSyncInterval = interval
+		}
+	}
 
 	// Load Google Ads environment variables
 	googleClientID = os.Getenv("GOOGLE_CLIENT_ID")
@@ -97,6 +131,13 @@ func main() {
 	// Rota para webhook da Kirvano
 	r.POST("/webhook/kirvano", handleKirvano)
 
+	// Rotas para webhook do Meta (Lead Ads)
+	r.GET("/webhook/meta", verifyMetaWebhook)
+	r.POST("/webhook/meta", handleMetaWebhook)
+
+	// Relatório de atribuição de vendas às campanhas
+	r.GET("/relatorios/atribuicao", getAttributionReport)
+
 	// Rotas para integração com Meta Ads
 	r.POST("/meta-ads/metricas", getMetaAdsMetricas)
 	r.GET("/meta-ads/metricas", getMetaAdsMetricas)      // Suporte para GET
@@ -104,6 +145,16 @@ func main() {
 	r.GET("/api/meta-ads/metricas", getMetaAdsMetricas)  // Suporte para GET na rota do Swagger
 	r.GET("/meta-ads/campanha/:campaign_id", getMetaAdsCampaignInsights)
 	r.GET("/meta-ads/conta/:account_id", getMetaAdsAccountInsights)
+	r.GET("/meta-ads/account-info/:account_id", getMetaAdsAccountInfo)
+	r.GET("/meta-ads/campanha/:campaign_id/conjuntos", getMetaAdsCampaignAdSets)
+	r.GET("/meta-ads/campanha/:campaign_id/anuncios", getMetaAdsAds)
+	r.GET("/meta-ads/conjunto/:adset_id/anuncios", getMetaAdsAds)
+	r.POST("/meta-ads/campanha/:campaign_id/alterar", updateMetaAdsObject)
+	r.POST("/meta-ads/conjunto/:adset_id/alterar", updateMetaAdsObject)
+	r.GET("/meta-ads/auditoria", listMetaAdsAudit)
+	r.POST("/meta-ads/relatorios", submitMetaAdsReport)
+	r.GET("/meta-ads/relatorios/:report_id", getMetaAdsReportStatus)
+	r.GET("/meta-ads/relatorios/:report_id/resultado", getMetaAdsReportResult)
 
 	// Nova rota para dados consolidados de todas as campanhas de todas as contas
 	r.POST("/meta-ads/consolidated", getMetaAdsConsolidatedData)
@@ -114,6 +165,13 @@ func main() {
 	// Novas rotas para autenticação OAuth do Meta Ads
 	r.GET("/meta-ads/auth", handleMetaAdsAuth)
 	r.GET("/meta-ads/callback", handleMetaAdsCallback)
+	r.GET("/meta-ads/token", getMetaAdsTokenInfo)
+	r.POST("/meta-ads/paginas", registerMetaAdsPages)
+	r.GET("/meta-ads/paginas", listMetaAdsPages)
+	r.GET("/meta-ads/leads", listMetaAdsLeads)
+	r.POST("/meta-ads/publicos", createMetaAdsAudience)
+	r.GET("/meta-ads/publicos", listMetaAdsAudiences)
+	r.POST("/meta-ads/publicos/:audience_id/sincronizar", syncMetaAdsAudience)
 
 	// Rotas para integração com Google Ads
 	r.POST("/google-ads/metricas", getGoogleAdsMetricas)
@@ -123,6 +181,13 @@ func main() {
 	r.GET("/google-ads/campanha/:campaign_id", getGoogleAdsCampaignInsights)
 	r.GET("/google-ads/conta/:account_id", getGoogleAdsAccountInsights)
 	r.GET("/google-ads/campanhas/:account_id", getGoogleAdsCampaigns)
+	r.GET("/google-ads/campanha/:campaign_id/grupos", getGoogleAdsAdGroups)
+	r.GET("/google-ads/campanha/:campaign_id/anuncios", getGoogleAdsAds)
+	r.GET("/google-ads/grupo/:ad_group_id/anuncios", getGoogleAdsAds)
+	r.GET("/google-ads/campanha/:campaign_id/palavras-chave", getGoogleAdsKeywords)
+	r.GET("/google-ads/grupo/:ad_group_id/palavras-chave", getGoogleAdsKeywords)
+	r.GET("/google-ads/termos-pesquisa", getGoogleAdsSearchTerms)
+	r.GET("/google-ads/termos-pesquisa/csv", exportGoogleAdsSearchTermsCSV)
 	// Nova rota para obter informações básicas da conta
 	r.GET("/google-ads/account-info/:account_id", getGoogleAdsAccountInfo)
 	// Rota de debug removida
@@ -148,6 +213,11 @@ func main() {
 	// Servir arquivos estáticos em diretórios específicos
 	r.Static("/static", "./static")
 
+	// Sincronizar periodicamente os públicos personalizados com os eventos recebidos
+	if metaAudienceSyncInterval > 0 {
+		newMetaAdsService().StartAudienceSync(metaAudiences, eventStore, metaAudienceSyncInterval, nil)
+	}
+
 	// Inicia o servidor na porta 8081
 	log.Println("Servidor iniciado em http://localhost:8081")
 	log.Println("Swagger UI disponível em http://localhost:8081/swagger/index.html")
@@ -225,6 +295,8 @@ func handleHotmart(c *gin.Context) {
 			webhook.Affiliates[0].AffiliateCode)
 	}
 
+	eventStore.Add(services.CheckoutEventFromHotmart(webhook))
+
 	response := models.HotmartResponse{
 		Status:  "success",
 		Message: "Webhook processado com sucesso",
@@ -305,6 +377,8 @@ func handleKiwify(c *gin.Context) {
 				webhook.TrackingData.UTMCampaign)
 		}
 
+		eventStore.Add(services.CheckoutEventFromKiwify(webhook))
+
 		response := models.KiwifyResponse{
 			Status:  "success",
 			Message: "Webhook processado com sucesso",
@@ -325,6 +399,8 @@ func handleKiwify(c *gin.Context) {
 			abandonedCart.Name,
 			abandonedCart.Email)
 
+		eventStore.Add(services.CheckoutEventFromKiwifyAbandonedCart(abandonedCart))
+
 		response := models.KiwifyResponse{
 			Status:  "success",
 			Message: "Webhook de carrinho abandonado processado com sucesso",
@@ -362,7 +438,8 @@ func handleKirvano(c *gin.Context) {
 		return
 	}
 
-	if webhook.SaleID == "" {
+	// Carrinhos abandonados não possuem venda, apenas checkout
+	if webhook.SaleID == "" && (webhook.Event != "ABANDONED_CART" || webhook.CheckoutID == "") {
 		respondWithError(c, http.StatusBadRequest, "ID da venda não fornecido")
 		return
 	}
@@ -396,6 +473,8 @@ func handleKirvano(c *gin.Context) {
 			webhook.UTM.UTMCampaign)
 	}
 
+	eventStore.Add(services.CheckoutEventFromKirvano(webhook))
+
 	response := models.KirvanoResponse{
 		Status:  "success",
 		Message: "Webhook processado com sucesso",
@@ -405,6 +484,162 @@ func handleKirvano(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// @Summary Verificação do webhook do Meta
+// @Description Responde ao handshake de verificação do Meta (hub.mode, hub.verify_token e hub.challenge)
+// @Produce plain
+// @Param hub.mode query string true "Sempre subscribe"
+// @Param hub.verify_token query string true "Token de verificação configurado em META_WEBHOOK_VERIFY_TOKEN"
+// @Param hub.challenge query string true "Valor a ser devolvido ao Meta"
+// @Success 200 {string} string "hub.challenge"
+// @Failure 403 {object} models.MetaWebhookResponse
+// @Router /webhook/meta [get]
+func verifyMetaWebhook(c *gin.Context) {
+	if metaWebhookVerifyToken == "" || c.Query("hub.mode") != "subscribe" || c.Query("hub.verify_token") != metaWebhookVerifyToken {
+		c.JSON(http.StatusForbidden, models.MetaWebhookResponse{
+			Status:  "error",
+			Message: "Token de verificação inválido",
+		})
+		return
+	}
+
+	c.String(http.StatusOK, c.Query("hub.challenge"))
+}
+
+// @Summary Webhook do Meta (Lead Ads)
+// @Description Recebe as notificações leadgen do Meta, valida o X-Hub-Signature-256, lê os dados do lead com o token da página e armazena o lead junto aos eventos de checkout
+// @Accept json
+// @Produce json
+// @Param X-Hub-Signature-256 header string true "Assinatura HMAC-SHA256 do corpo com o App Secret"
+// @Param webhook body models.MetaWebhookPayload true "Payload do webhook"
+// @Success 200 {object} models.MetaWebhookResponse
+// @Failure 400 {object} models.MetaWebhookResponse
+// @Failure 403 {object} models.MetaWebhookResponse
+// @Failure 500 {object} models.MetaWebhookResponse
+// @Router /webhook/meta [post]
+func handleMetaWebhook(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, "Erro ao ler payload")
+		return
+	}
+
+	if !services.ValidateMetaSignature(body, c.GetHeader("X-Hub-Signature-256"), metaAppSecret) {
+		log.Println("Webhook do Meta recebido com assinatura inválida")
+		c.JSON(http.StatusForbidden, models.MetaWebhookResponse{
+			Status:  "error",
+			Message: "Assinatura inválida",
+		})
+		return
+	}
+
+	var payload models.MetaWebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		respondWithError(c, http.StatusBadRequest, "JSON inválido para webhook do Meta")
+		return
+	}
+
+	metaAdsService := newMetaAdsService()
+
+	var leads []models.CheckoutEvent
+	var failures []string
+	for _, entry := range payload.Entry {
+		for _, change := range entry.Changes {
+			if change.Field != "leadgen" {
+				continue
+			}
+
+			if change.Value.PageID == "" {
+				change.Value.PageID = entry.ID
+			}
+
+			log.Printf("Novo lead do Meta recebido: LeadgenID=%s, Página=%s, Formulário=%s\n",
+				change.Value.LeadgenID,
+				change.Value.PageID,
+				change.Value.FormID)
+
+			lead, err := metaAdsService.GetLeadEvent(metaPageTokens, change.Value)
+			if err != nil {
+				log.Printf("Erro ao obter lead do Meta: %v\n", err)
+				failures = append(failures, err.Error())
+				continue
+			}
+
+			eventStore.Add(lead)
+			leads = append(leads, lead)
+		}
+	}
+
+	// Com falhas, o Meta reenvia a notificação; os leads já armazenados não são duplicados
+	if len(failures) > 0 {
+		c.JSON(http.StatusInternalServerError, models.MetaWebhookResponse{
+			Status:  "error",
+			Message: "Erro ao obter leads: " + strings.Join(failures, "; "),
+			Data:    leads,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MetaWebhookResponse{
+		Status:  "success",
+		Message: "Webhook processado com sucesso",
+		Data:    leads,
+	})
+}
+
+// @Summary Relatório de atribuição de vendas
+// @Description Distribui as vendas recebidas pelos webhooks entre as campanhas (UTMs) usando o histórico de eventos de cada cliente
+// @Tags Relatórios
+// @Produce json
+// @Param modelo query string false "Modelo de atribuição: first_touch, last_touch (padrão), linear ou time_decay"
+// @Param meia_vida_dias query number false "Meia-vida em dias para o modelo time_decay (padrão 7, mínimo 1 hora)"
+// @Success 200 {object} models.AttributionResponse
+// @Failure 400 {object} models.AttributionResponse
+// @Router /relatorios/atribuicao [get]
+func getAttributionReport(c *gin.Context) {
+	model, err := services.ParseAttributionModel(c.Query("modelo"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.AttributionResponse{
+			Success: false,
+			Message: "Modelo de atribuição inválido",
+			Error:   &models.ErrorInfo{Message: err.Error(), Type: "Validation Error"},
+		})
+		return
+	}
+
+	attributionService := services.NewAttributionService(eventStore)
+
+	if halfLife := c.Query("meia_vida_dias"); halfLife != "" {
+		days, err := strconv.ParseFloat(halfLife, 64)
+		minDays := services.MinTimeDecayHalfLife.Hours() / 24
+		if err != nil || math.IsNaN(days) || math.IsInf(days, 0) || days < minDays {
+			c.JSON(http.StatusBadRequest, models.AttributionResponse{
+				Success: false,
+				Message: "Meia-vida inválida",
+				Error:   &models.ErrorInfo{Message: fmt.Sprintf("meia_vida_dias deve ser um número de no mínimo %g (1 hora)", minDays), Type: "Validation Error"},
+			})
+			return
+		}
+		attributionService.HalfLife = time.Duration(days * float64(24*time.Hour))
+	}
+
+	results, err := attributionService.Attribute(model)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.AttributionResponse{
+			Success: false,
+			Message: "Erro ao calcular atribuição",
+			Error:   extractErrorInfo(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AttributionResponse{
+		Success: true,
+		Message: "Atribuição calculada com sucesso",
+		Modelo:  string(model),
+		Data:    results,
+	})
+}
+
 // Endpoint para obter métricas do Meta Ads
 // @Summary Obter métricas do Meta Ads
 // @Description Obtém métricas como CTR, CAC, investimento total e número de vendas do Meta Ads
@@ -412,13 +647,17 @@ func handleKirvano(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param request body models.MetaAdsRequest true "Token de acesso do Meta Ads"
+// @Param date_preset query string false "Período predefinido do Meta (ex: last_7d, last_month, maximum); padrão last_30d"
+// @Param since query string false "Data inicial (AAAA-MM-DD); exige until"
+// @Param until query string false "Data final (AAAA-MM-DD); exige since"
 // @Success 200 {object} models.MetaAdsResponse
 // @Failure 400 {object} models.MetaAdsResponse
 // @Failure 500 {object} models.MetaAdsResponse
 // @Router /meta-ads/metricas [post]
 func getMetaAdsMetricas(c *gin.Context) {
-	// Variável para armazenar o token
+	// Variáveis para armazenar o token e as opções da consulta
 	var token string
+	opts := metaInsightsOptionsFromQuery(c)
 
 	// Verificar o método da requisição
 	if c.Request.Method == "GET" {
@@ -444,13 +683,20 @@ func getMetaAdsMetricas(c *gin.Context) {
 			return
 		}
 		token = request.Token
+		if len(request.ActionTypes) > 0 {
+			opts.ActionTypes = request.ActionTypes
+		}
+	}
+
+	if respondMetaInvalidOptions(c, opts) || respondMetaUnsupportedOptions(c, opts) {
+		return
 	}
 
 	// Criar o serviço Meta Ads
-	metaAdsService := services.NewMetaAdsService()
+	metaAdsService := newMetaAdsService()
 
 	// Obter métricas do Meta Ads
-	data, err := metaAdsService.GetMetricas(token)
+	data, err := metaAdsService.GetMetricas(token, opts)
 	if err != nil {
 		// Usar dados simulados em caso de erro, mas incluir detalhes do erro
 		response, _ := metaAdsService.FallbackToMockData(err)
@@ -474,7 +720,14 @@ func getMetaAdsMetricas(c *gin.Context) {
 // @Produce json
 // @Param campaign_id path string true "ID da campanha"
 // @Param token query string true "Token de acesso do Meta Ads"
-// @Success 200 {object} models.MetaAdsResponse
+// @Param action_types query string false "Tipos de ação contados como venda, separados por vírgula (padrão: purchase)"
+// @Param date_preset query string false "Período predefinido do Meta (ex: last_7d, last_month, maximum); padrão last_30d"
+// @Param since query string false "Data inicial (AAAA-MM-DD); exige until"
+// @Param until query string false "Data final (AAAA-MM-DD); exige since"
+// @Param time_increment query string false "daily, weekly, monthly ou número de dias; retorna uma série temporal"
+// @Param breakdowns query string false "Segmentação separada por vírgula: age, gender, publisher_platform, platform_position, device_platform, region, country"
+// @Param action_breakdowns query string false "Segmentação das ações separada por vírgula (ex: action_device, action_destination)"
+// @Success 200 {object} models.MetaAdsResponse "Agregado do período; models.MetaAdsSegmentsResponse com breakdowns ou models.MetaAdsSeriesResponse com time_increment"
 // @Failure 400 {object} models.MetaAdsResponse
 // @Failure 500 {object} models.MetaAdsResponse
 // @Router /meta-ads/campanha/{campaign_id} [get]
@@ -491,11 +744,56 @@ func getMetaAdsCampaignInsights(c *gin.Context) {
 		return
 	}
 
+	opts := metaInsightsOptionsFromQuery(c)
+	if respondMetaInvalidOptions(c, opts) {
+		return
+	}
+
 	// Criar o serviço Meta Ads
-	metaAdsService := services.NewMetaAdsService()
+	metaAdsService := newMetaAdsService()
+
+	// Com breakdowns, retornar as linhas segmentadas em vez do agregado
+	if opts.HasBreakdowns() {
+		segments, err := metaAdsService.GetCampaignInsightsSegments(token, campaignID, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.MetaAdsSegmentsResponse{
+				Success: false,
+				Message: "Erro ao obter insights segmentados da campanha",
+				Error:   extractErrorInfo(err),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.MetaAdsSegmentsResponse{
+			Success: true,
+			Message: "Insights segmentados da campanha obtidos com sucesso",
+			Data:    segments,
+		})
+		return
+	}
+
+	// Com time_increment, retornar a série temporal em vez do agregado
+	if opts.TimeIncrement != "" {
+		series, err := metaAdsService.GetCampaignInsightsSeries(token, campaignID, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.MetaAdsSeriesResponse{
+				Success: false,
+				Message: "Erro ao obter série de insights da campanha",
+				Error:   extractErrorInfo(err),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.MetaAdsSeriesResponse{
+			Success: true,
+			Message: "Série de insights da campanha obtida com sucesso",
+			Data:    series,
+		})
+		return
+	}
 
 	// Obter insights da campanha
-	data, err := metaAdsService.GetCampaignInsights(token, campaignID)
+	data, err := metaAdsService.GetCampaignInsights(token, campaignID, opts)
 	if err != nil {
 		// Usar dados simulados em caso de erro, mas incluir detalhes do erro
 		response, _ := metaAdsService.FallbackToMockData(err)
@@ -519,7 +817,14 @@ func getMetaAdsCampaignInsights(c *gin.Context) {
 // @Produce json
 // @Param account_id path string true "ID da conta de anúncios"
 // @Param token query string true "Token de acesso do Meta Ads"
-// @Success 200 {object} models.MetaAdsResponse
+// @Param action_types query string false "Tipos de ação contados como venda, separados por vírgula (padrão: purchase)"
+// @Param date_preset query string false "Período predefinido do Meta (ex: last_7d, last_month, maximum); padrão last_30d"
+// @Param since query string false "Data inicial (AAAA-MM-DD); exige until"
+// @Param until query string false "Data final (AAAA-MM-DD); exige since"
+// @Param time_increment query string false "daily, weekly, monthly ou número de dias; retorna uma série temporal"
+// @Param breakdowns query string false "Segmentação separada por vírgula: age, gender, publisher_platform, platform_position, device_platform, region, country"
+// @Param action_breakdowns query string false "Segmentação das ações separada por vírgula (ex: action_device, action_destination)"
+// @Success 200 {object} models.MetaAdsResponse "Agregado do período; models.MetaAdsSegmentsResponse com breakdowns ou models.MetaAdsSeriesResponse com time_increment"
 // @Failure 400 {object} models.MetaAdsResponse
 // @Failure 500 {object} models.MetaAdsResponse
 // @Router /meta-ads/conta/{account_id} [get]
@@ -536,11 +841,56 @@ func getMetaAdsAccountInsights(c *gin.Context) {
 		return
 	}
 
+	opts := metaInsightsOptionsFromQuery(c)
+	if respondMetaInvalidOptions(c, opts) {
+		return
+	}
+
 	// Criar o serviço Meta Ads
-	metaAdsService := services.NewMetaAdsService()
+	metaAdsService := newMetaAdsService()
+
+	// Com breakdowns, retornar as linhas segmentadas em vez do agregado
+	if opts.HasBreakdowns() {
+		segments, err := metaAdsService.GetAccountInsightsSegments(token, accountID, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.MetaAdsSegmentsResponse{
+				Success: false,
+				Message: "Erro ao obter insights segmentados da conta",
+				Error:   extractErrorInfo(err),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.MetaAdsSegmentsResponse{
+			Success: true,
+			Message: "Insights segmentados da conta obtidos com sucesso",
+			Data:    segments,
+		})
+		return
+	}
+
+	// Com time_increment, retornar a série temporal em vez do agregado
+	if opts.TimeIncrement != "" {
+		series, err := metaAdsService.GetAccountInsightsSeries(token, accountID, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.MetaAdsSeriesResponse{
+				Success: false,
+				Message: "Erro ao obter série de insights da conta",
+				Error:   extractErrorInfo(err),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.MetaAdsSeriesResponse{
+			Success: true,
+			Message: "Série de insights da conta obtida com sucesso",
+			Data:    series,
+		})
+		return
+	}
 
 	// Obter insights da conta
-	data, err := metaAdsService.GetAccountInsights(token, accountID)
+	data, err := metaAdsService.GetAccountInsights(token, accountID, opts)
 	if err != nil {
 		// Usar dados simulados em caso de erro, mas incluir detalhes do erro
 		response, _ := metaAdsService.FallbackToMockData(err)
@@ -556,13 +906,498 @@ func getMetaAdsAccountInsights(c *gin.Context) {
 	})
 }
 
+// Endpoint para listar os conjuntos de anúncios de uma campanha do Meta Ads
+// @Summary Listar conjuntos de anúncios de uma campanha do Meta Ads
+// @Description Lista os conjuntos de anúncios da campanha com status, orçamento, estratégia de lance e métricas do período
+// @Tags Meta Ads
+// @Produce json
+// @Param campaign_id path string true "ID da campanha"
+// @Param token query string true "Token de acesso do Meta Ads"
+// @Param action_types query string false "Tipos de ação contados como venda, separados por vírgula (padrão: purchase)"
+// @Param date_preset query string false "Período predefinido do Meta (ex: last_7d, last_month, maximum); padrão last_30d"
+// @Param since query string false "Data inicial (AAAA-MM-DD); exige until"
+// @Param until query string false "Data final (AAAA-MM-DD); exige since"
+// @Success 200 {object} models.MetaAdSetListResponse
+// @Failure 400 {object} models.MetaAdSetListResponse
+// @Failure 500 {object} models.MetaAdSetListResponse
+// @Router /meta-ads/campanha/{campaign_id}/conjuntos [get]
+func getMetaAdsCampaignAdSets(c *gin.Context) {
+	campaignID := c.Param("campaign_id")
+	token := c.Query("token")
+
+	if campaignID == "" || token == "" {
+		c.JSON(http.StatusBadRequest, models.MetaAdSetListResponse{
+			Success: false,
+			Message: "ID da campanha e token são obrigatórios",
+			Error:   &models.ErrorInfo{Message: "campaign_id e token são obrigatórios", Type: "Validation Error"},
+		})
+		return
+	}
+
+	opts := metaInsightsOptionsFromQuery(c)
+	if respondMetaInvalidOptions(c, opts) || respondMetaUnsupportedOptions(c, opts) {
+		return
+	}
+
+	metaAdsService := newMetaAdsService()
+
+	adSets, err := metaAdsService.GetCampaignAdSets(token, campaignID, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.MetaAdSetListResponse{
+			Success: false,
+			Message: "Erro ao listar conjuntos de anúncios",
+			Error:   extractErrorInfo(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MetaAdSetListResponse{
+		Success: true,
+		Message: "Conjuntos de anúncios listados com sucesso",
+		Data:    adSets,
+	})
+}
+
+// Endpoint para listar os anúncios de uma campanha ou de um conjunto de anúncios do Meta Ads
+// @Summary Listar anúncios do Meta Ads
+// @Description Lista os anúncios de uma campanha ou de um conjunto de anúncios com status, criativo e métricas do período
+// @Tags Meta Ads
+// @Produce json
+// @Param campaign_id path string false "ID da campanha"
+// @Param adset_id path string false "ID do conjunto de anúncios"
+// @Param token query string true "Token de acesso do Meta Ads"
+// @Param action_types query string false "Tipos de ação contados como venda, separados por vírgula (padrão: purchase)"
+// @Param date_preset query string false "Período predefinido do Meta (ex: last_7d, last_month, maximum); padrão last_30d"
+// @Param since query string false "Data inicial (AAAA-MM-DD); exige until"
+// @Param until query string false "Data final (AAAA-MM-DD); exige since"
+// @Success 200 {object} models.MetaAdListResponse
+// @Failure 400 {object} models.MetaAdListResponse
+// @Failure 500 {object} models.MetaAdListResponse
+// @Router /meta-ads/campanha/{campaign_id}/anuncios [get]
+// @Router /meta-ads/conjunto/{adset_id}/anuncios [get]
+func getMetaAdsAds(c *gin.Context) {
+	parentID := c.Param("campaign_id")
+	if parentID == "" {
+		parentID = c.Param("adset_id")
+	}
+	token := c.Query("token")
+
+	if parentID == "" || token == "" {
+		c.JSON(http.StatusBadRequest, models.MetaAdListResponse{
+			Success: false,
+			Message: "ID da campanha ou do conjunto e token são obrigatórios",
+			Error:   &models.ErrorInfo{Message: "campaign_id/adset_id e token são obrigatórios", Type: "Validation Error"},
+		})
+		return
+	}
+
+	opts := metaInsightsOptionsFromQuery(c)
+	if respondMetaInvalidOptions(c, opts) || respondMetaUnsupportedOptions(c, opts) {
+		return
+	}
+
+	metaAdsService := newMetaAdsService()
+
+	ads, err := metaAdsService.GetAds(token, parentID, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.MetaAdListResponse{
+			Success: false,
+			Message: "Erro ao listar anúncios",
+			Error:   extractErrorInfo(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MetaAdListResponse{
+		Success: true,
+		Message: "Anúncios listados com sucesso",
+		Data:    ads,
+	})
+}
+
+// Endpoint para obter as informações de uma conta de anúncios do Meta Ads
+// @Summary Obter informações da conta do Meta Ads
+// @Description Obtém moeda, fuso horário, status, limite de gastos, valor gasto e Business Manager da conta de anúncios
+// @Tags Meta Ads
+// @Produce json
+// @Param account_id path string true "ID da conta de anúncios"
+// @Param token query string true "Token de acesso do Meta Ads"
+// @Success 200 {object} models.MetaAdsAccountInfoResponse
+// @Failure 400 {object} models.MetaAdsAccountInfoResponse
+// @Failure 500 {object} models.MetaAdsAccountInfoResponse
+// @Router /meta-ads/account-info/{account_id} [get]
+func getMetaAdsAccountInfo(c *gin.Context) {
+	accountID := c.Param("account_id")
+	token := c.Query("token")
+
+	if accountID == "" || token == "" {
+		c.JSON(http.StatusBadRequest, models.MetaAdsAccountInfoResponse{
+			Success: false,
+			Message: "ID da conta e token são obrigatórios",
+			Error:   &models.ErrorInfo{Message: "token e account_id são obrigatórios", Type: "Validation Error"},
+		})
+		return
+	}
+
+	accountInfo, err := newMetaAdsService().GetAccountInfo(token, accountID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.MetaAdsAccountInfoResponse{
+			Success: false,
+			Message: "Erro ao obter informações da conta",
+			Error:   extractErrorInfo(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MetaAdsAccountInfoResponse{
+		Success: true,
+		Message: "Informações da conta obtidas com sucesso",
+		Data:    accountInfo,
+	})
+}
+
+// Endpoint para alterar o status, o orçamento ou a data de término de uma campanha ou conjunto de anúncios do Meta Ads
+// @Summary Alterar campanha ou conjunto de anúncios do Meta Ads
+// @Description Pausa ou ativa (ACTIVE/PAUSED), altera o orçamento diário ou total e a data de término. Com dry_run a alteração é apenas simulada. Toda alteração é registrada em /meta-ads/auditoria
+// @Tags Meta Ads
+// @Accept json
+// @Produce json
+// @Param campaign_id path string false "ID da campanha"
+// @Param adset_id path string false "ID do conjunto de anúncios"
+// @Param request body models.MetaUpdateRequest true "Alterações solicitadas"
+// @Success 200 {object} models.MetaUpdateResponse
+// @Failure 400 {object} models.MetaUpdateResponse
+// @Failure 500 {object} models.MetaUpdateResponse
+// @Router /meta-ads/campanha/{campaign_id}/alterar [post]
+// @Router /meta-ads/conjunto/{adset_id}/alterar [post]
+func updateMetaAdsObject(c *gin.Context) {
+	var request models.MetaUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.MetaUpdateResponse{
+			Success: false,
+			Message: "Token é obrigatório",
+			Error:   &models.ErrorInfo{Message: err.Error(), Type: "Validation Error"},
+		})
+		return
+	}
+
+	metaAdsService := newMetaAdsService()
+
+	var record *models.MetaAuditRecord
+	var err error
+	if campaignID := c.Param("campaign_id"); campaignID != "" {
+		record, err = metaAdsService.UpdateCampaign(metaAuditLog, campaignID, request)
+	} else {
+		record, err = metaAdsService.UpdateAdSet(metaAuditLog, c.Param("adset_id"), request)
+	}
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		errorInfo := extractErrorInfo(err)
+		if errors.Is(err, services.ErrInvalidMetaUpdate) {
+			status = http.StatusBadRequest
+			errorInfo.Type = "Validation Error"
+		}
+		c.JSON(status, models.MetaUpdateResponse{
+			Success: false,
+			Message: "Erro ao alterar no Meta Ads",
+			Data:    record,
+			Error:   errorInfo,
+		})
+		return
+	}
+
+	message := "Alteração aplicada com sucesso"
+	if record.DryRun {
+		message = "Simulação concluída; nenhuma alteração foi enviada ao Meta Ads"
+	}
+
+	c.JSON(http.StatusOK, models.MetaUpdateResponse{
+		Success: true,
+		Message: message,
+		Data:    record,
+	})
+}
+
+// Endpoint para listar o histórico de alterações feitas no Meta Ads
+// @Summary Histórico de alterações do Meta Ads
+// @Description Lista as alterações (e simulações) feitas em campanhas e conjuntos de anúncios, com o usuário, os valores anteriores e os novos
+// @Tags Meta Ads
+// @Produce json
+// @Success 200 {object} models.MetaAuditListResponse
+// @Router /meta-ads/auditoria [get]
+func listMetaAdsAudit(c *gin.Context) {
+	c.JSON(http.StatusOK, models.MetaAuditListResponse{
+		Success: true,
+		Message: "Histórico de alterações obtido com sucesso",
+		Data:    metaAuditLog.List(),
+	})
+}
+
+// Endpoint para criar um público personalizado do Meta Ads a partir dos eventos de checkout
+// @Summary Criar público personalizado do Meta Ads
+// @Description Cria um público personalizado de compradores ou de carrinhos abandonados (últimos janela_dias dias), opcionalmente filtrado por produto. Os membros são enviados com e-mail e telefone normalizados em SHA-256 na sincronização
+// @Tags Meta Ads
+// @Accept json
+// @Produce json
+// @Param request body models.MetaAudienceRequest true "Definição do público"
+// @Success 201 {object} models.MetaAudienceResponse
+// @Failure 400 {object} models.MetaAudienceResponse
+// @Failure 500 {object} models.MetaAudienceResponse
+// @Router /meta-ads/publicos [post]
+func createMetaAdsAudience(c *gin.Context) {
+	var request models.MetaAudienceRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.MetaAudienceResponse{
+			Success: false,
+			Message: "Token, conta_id, nome e tipo são obrigatórios",
+			Error:   &models.ErrorInfo{Message: err.Error(), Type: "Validation Error"},
+		})
+		return
+	}
+
+	audience, err := newMetaAdsService().CreateCustomAudience(metaAudiences, request)
+	if err != nil {
+		status := http.StatusInternalServerError
+		errorInfo := extractErrorInfo(err)
+		if errors.Is(err, services.ErrInvalidMetaAudience) {
+			status = http.StatusBadRequest
+			errorInfo.Type = "Validation Error"
+		}
+		c.JSON(status, models.MetaAudienceResponse{
+			Success: false,
+			Message: "Erro ao criar o público personalizado",
+			Error:   errorInfo,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.MetaAudienceResponse{
+		Success: true,
+		Message: "Público personalizado criado; use /meta-ads/publicos/" + audience.ID + "/sincronizar para enviar os membros",
+		Data:    audience,
+	})
+}
+
+// Endpoint para listar os públicos personalizados sincronizados
+// @Summary Listar públicos personalizados do Meta Ads
+// @Description Lista os públicos criados por este servidor, com o número de membros e a última sincronização
+// @Tags Meta Ads
+// @Produce json
+// @Success 200 {object} models.MetaAudienceListResponse
+// @Router /meta-ads/publicos [get]
+func listMetaAdsAudiences(c *gin.Context) {
+	c.JSON(http.StatusOK, models.MetaAudienceListResponse{
+		Success: true,
+		Message: "Públicos personalizados obtidos com sucesso",
+		Data:    metaAudiences.List(),
+	})
+}
+
+// Endpoint para sincronizar um público personalizado com os eventos de checkout
+// @Summary Sincronizar público personalizado do Meta Ads
+// @Description Envia ao Meta apenas as diferenças desde a última sincronização: adiciona novos clientes e remove quem deixou de se qualificar (por exemplo, após um reembolso)
+// @Tags Meta Ads
+// @Produce json
+// @Param audience_id path string true "ID do público"
+// @Success 200 {object} models.MetaAudienceSyncResponse
+// @Failure 404 {object} models.MetaAudienceSyncResponse
+// @Failure 500 {object} models.MetaAudienceSyncResponse
+// @Router /meta-ads/publicos/{audience_id}/sincronizar [post]
+func syncMetaAdsAudience(c *gin.Context) {
+	result, err := newMetaAdsService().SyncCustomAudience(metaAudiences, eventStore, c.Param("audience_id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrMetaAudienceNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, models.MetaAudienceSyncResponse{
+			Success: false,
+			Message: "Erro ao sincronizar o público personalizado",
+			Error:   extractErrorInfo(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MetaAudienceSyncResponse{
+		Success: true,
+		Message: "Público sincronizado com sucesso",
+		Data:    result,
+	})
+}
+
+// Endpoint para enviar um relatório assíncrono de insights do Meta Ads
+// @Summary Enviar relatório assíncrono do Meta Ads
+// @Description Envia um relatório assíncrono de insights para contas grandes; consulte o andamento em /meta-ads/relatorios/{report_id}
+// @Tags Meta Ads
+// @Accept json
+// @Produce json
+// @Param request body models.MetaReportJobRequest true "Conta, nível e período do relatório"
+// @Success 202 {object} models.MetaReportJobResponse
+// @Failure 400 {object} models.MetaReportJobResponse
+// @Failure 500 {object} models.MetaReportJobResponse
+// @Router /meta-ads/relatorios [post]
+func submitMetaAdsReport(c *gin.Context) {
+	var request models.MetaReportJobRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.MetaReportJobResponse{
+			Success: false,
+			Message: "Token e conta_id são obrigatórios",
+			Error:   &models.ErrorInfo{Message: err.Error(), Type: "Validation Error"},
+		})
+		return
+	}
+
+	opts := services.MetaInsightsOptions{
+		ActionTypes:      request.ActionTypes,
+		DatePreset:       request.DatePreset,
+		Since:            request.Since,
+		Until:            request.Until,
+		TimeIncrement:    request.TimeIncrement,
+		Breakdowns:       request.Breakdowns,
+		ActionBreakdowns: request.ActionBreakdowns,
+	}
+	err := opts.Validate()
+	if err == nil {
+		err = services.ValidateMetaReportLevel(request.Nivel)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.MetaReportJobResponse{
+			Success: false,
+			Message: "Parâmetros do relatório inválidos",
+			Error:   &models.ErrorInfo{Message: err.Error(), Type: "Validation Error"},
+		})
+		return
+	}
+
+	metaAdsService := newMetaAdsService()
+
+	job, err := metaAdsService.SubmitInsightsReport(metaReportJobs, request.Token, request.ContaID, request.Nivel, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.MetaReportJobResponse{
+			Success: false,
+			Message: "Erro ao enviar relatório assíncrono",
+			Error:   extractErrorInfo(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.MetaReportJobResponse{
+		Success: true,
+		Message: "Relatório enviado ao Meta Ads",
+		Data:    job,
+	})
+}
+
+// Endpoint para consultar o andamento de um relatório assíncrono do Meta Ads
+// @Summary Consultar relatório assíncrono do Meta Ads
+// @Description Consulta o status (async_status) e o percentual concluído de um relatório assíncrono
+// @Tags Meta Ads
+// @Produce json
+// @Param report_id path string true "ID do relatório"
+// @Param token query string true "Token de acesso do Meta Ads"
+// @Success 200 {object} models.MetaReportJobResponse
+// @Failure 400 {object} models.MetaReportJobResponse
+// @Failure 404 {object} models.MetaReportJobResponse
+// @Failure 500 {object} models.MetaReportJobResponse
+// @Router /meta-ads/relatorios/{report_id} [get]
+func getMetaAdsReportStatus(c *gin.Context) {
+	reportID := c.Param("report_id")
+	token := c.Query("token")
+
+	if token == "" {
+		c.JSON(http.StatusBadRequest, models.MetaReportJobResponse{
+			Success: false,
+			Message: "Token é obrigatório",
+			Error:   &models.ErrorInfo{Message: "token é obrigatório", Type: "Validation Error"},
+		})
+		return
+	}
+
+	metaAdsService := newMetaAdsService()
+
+	job, err := metaAdsService.GetInsightsReportStatus(metaReportJobs, token, reportID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrMetaReportNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, models.MetaReportJobResponse{
+			Success: false,
+			Message: "Erro ao consultar relatório assíncrono",
+			Error:   extractErrorInfo(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MetaReportJobResponse{
+		Success: true,
+		Message: "Status do relatório obtido com sucesso",
+		Data:    job,
+	})
+}
+
+// Endpoint para baixar o resultado de um relatório assíncrono do Meta Ads
+// @Summary Obter resultado de relatório assíncrono do Meta Ads
+// @Description Baixa todas as linhas de um relatório assíncrono concluído, com as mesmas métricas das demais consultas
+// @Tags Meta Ads
+// @Produce json
+// @Param report_id path string true "ID do relatório"
+// @Param token query string true "Token de acesso do Meta Ads"
+// @Success 200 {object} models.MetaReportResultResponse
+// @Failure 400 {object} models.MetaReportResultResponse
+// @Failure 404 {object} models.MetaReportResultResponse
+// @Failure 409 {object} models.MetaReportResultResponse
+// @Failure 500 {object} models.MetaReportResultResponse
+// @Router /meta-ads/relatorios/{report_id}/resultado [get]
+func getMetaAdsReportResult(c *gin.Context) {
+	reportID := c.Param("report_id")
+	token := c.Query("token")
+
+	if token == "" {
+		c.JSON(http.StatusBadRequest, models.MetaReportResultResponse{
+			Success: false,
+			Message: "Token é obrigatório",
+			Error:   &models.ErrorInfo{Message: "token é obrigatório", Type: "Validation Error"},
+		})
+		return
+	}
+
+	metaAdsService := newMetaAdsService()
+
+	job, rows, err := metaAdsService.GetInsightsReportResults(metaReportJobs, token, reportID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrMetaReportNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, services.ErrMetaReportNotReady):
+			status = http.StatusConflict
+		}
+		c.JSON(status, models.MetaReportResultResponse{
+			Success: false,
+			Message: "Resultado do relatório indisponível",
+			Job:     job,
+			Error:   extractErrorInfo(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MetaReportResultResponse{
+		Success: true,
+		Message: "Resultado do relatório obtido com sucesso",
+		Job:     job,
+		Data:    rows,
+	})
+}
+
 // @Summary Obter métricas do Google Ads
 // @Description Obtém métricas como CTR, CPC, conversões e investimento total do Google Ads
 // @Tags Google Ads
 // @Accept json
 // @Produce json
-// @Param request body models.GoogleAdsRequest true "Credenciais de acesso do Google Ads"
-// @Success 200 {object} models.GoogleAdsResponse
+// @Param request body models.GoogleAdsRequest true "Credenciais de acesso do Google Ads, account_id (obrigatório quando há mais de uma conta cliente), período (date_range ou since/until), segment e conversion_actions opcionais"
+// @Success 200 {object} models.GoogleAdsResponse "Agregado do período; models.GoogleAdsSeriesResponse com segment"
 // @Failure 400 {object} models.GoogleAdsResponse
 // @Failure 500 {object} models.GoogleAdsResponse
 // @Router /google-ads/metricas [post]
@@ -576,6 +1411,12 @@ func getGoogleAdsMetricas(c *gin.Context) {
 		request.ClientSecret = c.Query("client_secret")
 		request.RefreshToken = c.Query("refresh_token")
 		request.ManagerID = c.Query("manager_id")
+		request.AccountID = c.Query("account_id")
+		request.DateRange = c.Query("date_range")
+		request.Since = c.Query("since")
+		request.Until = c.Query("until")
+		request.Segment = c.Query("segment")
+		request.ConversionActions = splitQueryList(c.Query("conversion_actions"))
 	} else {
 		// Para POST, extrair parâmetros do corpo da requisição
 		body, err := io.ReadAll(c.Request.Body)
@@ -596,11 +1437,39 @@ func getGoogleAdsMetricas(c *gin.Context) {
 		return
 	}
 
+	opts := googleAdsReportOptionsFromRequest(request)
+	if respondGoogleInvalidOptions(c, opts) {
+		return
+	}
+
 	// Criar serviço do Google Ads
-	googleAdsService := services.NewGoogleAdsService()
+	googleAdsService := newGoogleAdsService(request.ManagerID)
+
+	// Com segment, retornar a série temporal em vez do agregado
+	if opts.Segment != "" {
+		series, err := googleAdsService.GetMetricasSeries(request.ClientID, request.ClientSecret, request.RefreshToken, request.ManagerID, request.AccountID, opts)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.GoogleAdsSeriesResponse{
+				Success: false,
+				Message: "Erro ao obter série de métricas do Google Ads",
+				Error: &models.ErrorInfo{
+					Message: err.Error(),
+					Type:    "API Error",
+				},
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.GoogleAdsSeriesResponse{
+			Success: true,
+			Message: "Série de métricas obtida com sucesso",
+			Data:    series,
+		})
+		return
+	}
 
 	// Obter métricas
-	data, err := googleAdsService.GetMetricas(request.ClientID, request.ClientSecret, request.RefreshToken, request.ManagerID)
+	data, err := googleAdsService.GetMetricas(request.ClientID, request.ClientSecret, request.RefreshToken, request.ManagerID, request.AccountID, opts)
 	if err != nil {
 		// Retornar erro ao cliente
 		c.JSON(http.StatusBadRequest, models.GoogleAdsResponse{
@@ -630,16 +1499,25 @@ func getGoogleAdsMetricas(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param campaign_id path string true "ID da campanha"
+// @Param account_id query string true "ID da conta de anúncios dona da campanha"
 // @Param client_id query string true "ID do cliente OAuth"
 // @Param client_secret query string true "Secret do cliente OAuth"
 // @Param refresh_token query string true "Token de atualização OAuth"
-// @Success 200 {object} models.GoogleAdsResponse
+// @Param manager_id query string false "ID da conta gerenciadora (MCC), enviado como login-customer-id"
+// @Param date_range query string false "Período predefinido do GAQL (ex: LAST_7_DAYS, LAST_MONTH); padrão LAST_30_DAYS"
+// @Param since query string false "Data inicial (AAAA-MM-DD); exige until"
+// @Param until query string false "Data final (AAAA-MM-DD); exige since"
+// @Param segment query string false "date, week ou month; retorna uma série temporal"
+// @Param breakdowns query string false "Breakdowns separados por vírgula (device, ad_network_type, geo, hour); retorna linhas segmentadas"
+// @Param conversion_actions query string false "IDs das ações de conversão contadas, separados por vírgula; padrão: as incluídas em conversões na conta"
+// @Success 200 {object} models.GoogleAdsResponse "Agregado do período; models.GoogleAdsSeriesResponse com segment; models.GoogleAdsSegmentsResponse com breakdowns"
 // @Failure 400 {object} models.GoogleAdsResponse
 // @Failure 500 {object} models.GoogleAdsResponse
 // @Router /google-ads/campanha/{campaign_id} [get]
 func getGoogleAdsCampaignInsights(c *gin.Context) {
 	// Extrair parâmetros
 	campaignID := c.Param("campaign_id")
+	accountID := c.Query("account_id")
 	clientID := c.Query("client_id")
 	clientSecret := c.Query("client_secret")
 	refreshToken := c.Query("refresh_token")
@@ -650,19 +1528,66 @@ func getGoogleAdsCampaignInsights(c *gin.Context) {
 		return
 	}
 
+	if accountID == "" {
+		respondWithError(c, http.StatusBadRequest, "ID da conta (account_id) não fornecido")
+		return
+	}
+
 	if clientID == "" || clientSecret == "" || refreshToken == "" {
 		respondWithError(c, http.StatusBadRequest, "Credenciais incompletas")
 		return
 	}
 
+	opts := googleAdsReportOptionsFromQuery(c)
+	if respondGoogleInvalidOptions(c, opts) {
+		return
+	}
+
 	// Criar serviço do Google Ads
-	googleAdsService := services.NewGoogleAdsService()
+	googleAdsService := newGoogleAdsService(c.Query("manager_id"))
+
+	// Com breakdowns, retornar as linhas segmentadas em vez do agregado
+	if opts.HasBreakdowns() {
+		segments, err := googleAdsService.GetCampaignInsightsSegments(clientID, clientSecret, refreshToken, accountID, campaignID, opts)
+		if err != nil {
+			status, errorInfo := googleAdsErrorInfo(err)
+			c.JSON(status, models.GoogleAdsSegmentsResponse{
+				Success: false,
+				Message: "Erro ao obter insights segmentados da campanha",
+				Error:   errorInfo,
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.GoogleAdsSegmentsResponse{
+			Success: true,
+			Message: "Insights segmentados da campanha obtidos com sucesso",
+			Data:    segments,
+		})
+		return
+	}
+
+	// Com segment, retornar a série temporal em vez do agregado
+	if opts.Segment != "" {
+		series, err := googleAdsService.GetCampaignInsightsSeries(clientID, clientSecret, refreshToken, accountID, campaignID, opts)
+		if err != nil {
+			respondGoogleAdsSeriesError(c, "Erro ao obter série de insights da campanha", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, models.GoogleAdsSeriesResponse{
+			Success: true,
+			Message: "Série de insights da campanha obtida com sucesso",
+			Data:    series,
+		})
+		return
+	}
 
 	// Obter insights da campanha
-	data, err := googleAdsService.GetCampaignInsights(clientID, clientSecret, refreshToken, campaignID)
+	data, err := googleAdsService.GetCampaignInsights(clientID, clientSecret, refreshToken, accountID, campaignID, opts)
 	if err != nil {
 		// Extrair informações detalhadas do erro
-		errorInfo := extractErrorInfo(err)
+		status, errorInfo := googleAdsErrorInfo(err)
 
 		// Retornar resposta de erro
 		response := models.GoogleAdsResponse{
@@ -671,7 +1596,7 @@ func getGoogleAdsCampaignInsights(c *gin.Context) {
 			Error:   errorInfo,
 		}
 
-		c.JSON(http.StatusInternalServerError, response)
+		c.JSON(status, response)
 		return
 	}
 
@@ -694,7 +1619,14 @@ func getGoogleAdsCampaignInsights(c *gin.Context) {
 // @Param client_id query string true "ID do cliente OAuth"
 // @Param client_secret query string true "Secret do cliente OAuth"
 // @Param refresh_token query string true "Token de atualização OAuth"
-// @Success 200 {object} models.GoogleAdsResponse
+// @Param manager_id query string false "ID da conta gerenciadora (MCC), enviado como login-customer-id"
+// @Param date_range query string false "Período predefinido do GAQL (ex: LAST_7_DAYS, LAST_MONTH); padrão LAST_30_DAYS"
+// @Param since query string false "Data inicial (AAAA-MM-DD); exige until"
+// @Param until query string false "Data final (AAAA-MM-DD); exige since"
+// @Param segment query string false "date, week ou month; retorna uma série temporal"
+// @Param breakdowns query string false "Breakdowns separados por vírgula (device, ad_network_type, geo, hour); retorna linhas segmentadas"
+// @Param conversion_actions query string false "IDs das ações de conversão contadas, separados por vírgula; padrão: as incluídas em conversões na conta"
+// @Success 200 {object} models.GoogleAdsResponse "Agregado do período; models.GoogleAdsSeriesResponse com segment; models.GoogleAdsSegmentsResponse com breakdowns"
 // @Failure 400 {object} models.GoogleAdsResponse
 // @Failure 500 {object} models.GoogleAdsResponse
 // @Router /google-ads/conta/{account_id} [get]
@@ -716,11 +1648,53 @@ func getGoogleAdsAccountInsights(c *gin.Context) {
 		return
 	}
 
-	// Criar serviço do Google Ads
-	googleAdsService := services.NewGoogleAdsService()
-
+	opts := googleAdsReportOptionsFromQuery(c)
+	if respondGoogleInvalidOptions(c, opts) {
+		return
+	}
+
+	// Criar serviço do Google Ads
+	googleAdsService := newGoogleAdsService(c.Query("manager_id"))
+
+	// Com breakdowns, retornar as linhas segmentadas em vez do agregado
+	if opts.HasBreakdowns() {
+		segments, err := googleAdsService.GetAccountInsightsSegments(clientID, clientSecret, refreshToken, accountID, opts)
+		if err != nil {
+			status, errorInfo := googleAdsErrorInfo(err)
+			c.JSON(status, models.GoogleAdsSegmentsResponse{
+				Success: false,
+				Message: "Erro ao obter insights segmentados da conta",
+				Error:   errorInfo,
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.GoogleAdsSegmentsResponse{
+			Success: true,
+			Message: "Insights segmentados da conta obtidos com sucesso",
+			Data:    segments,
+		})
+		return
+	}
+
+	// Com segment, retornar a série temporal em vez do agregado
+	if opts.Segment != "" {
+		series, err := googleAdsService.GetAccountInsightsSeries(clientID, clientSecret, refreshToken, accountID, opts)
+		if err != nil {
+			respondGoogleAdsSeriesError(c, "Erro ao obter série de insights da conta", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, models.GoogleAdsSeriesResponse{
+			Success: true,
+			Message: "Série de insights da conta obtida com sucesso",
+			Data:    series,
+		})
+		return
+	}
+
 	// Obter insights da conta
-	data, err := googleAdsService.GetAccountInsights(clientID, clientSecret, refreshToken, accountID)
+	data, err := googleAdsService.GetAccountInsights(clientID, clientSecret, refreshToken, accountID, opts)
 	if err != nil {
 		// Extrair informações detalhadas do erro
 		errorInfo := extractErrorInfo(err)
@@ -755,6 +1729,12 @@ func getGoogleAdsAccountInsights(c *gin.Context) {
 // @Param client_id query string true "ID do cliente OAuth"
 // @Param client_secret query string true "Secret do cliente OAuth"
 // @Param refresh_token query string true "Token de atualização OAuth"
+// @Param manager_id query string false "ID da conta gerenciadora (MCC), enviado como login-customer-id"
+// @Param date_range query string false "Período predefinido do GAQL (ex: LAST_7_DAYS, LAST_MONTH); padrão LAST_30_DAYS"
+// @Param since query string false "Data inicial (AAAA-MM-DD); exige until"
+// @Param until query string false "Data final (AAAA-MM-DD); exige since"
+// @Param segment query string false "date, week ou month; cada campanha tem uma linha por período"
+// @Param conversion_actions query string false "IDs das ações de conversão contadas, separados por vírgula; padrão: as incluídas em conversões na conta"
 // @Success 200 {object} models.GoogleAdsCampaignListResponse
 // @Failure 400 {object} models.GoogleAdsCampaignListResponse
 // @Failure 500 {object} models.GoogleAdsCampaignListResponse
@@ -777,11 +1757,16 @@ func getGoogleAdsCampaigns(c *gin.Context) {
 		return
 	}
 
+	opts := googleAdsReportOptionsFromQuery(c)
+	if respondGoogleInvalidOptions(c, opts) || respondGoogleUnsupportedBreakdowns(c, opts) {
+		return
+	}
+
 	// Criar serviço do Google Ads
-	googleAdsService := services.NewGoogleAdsService()
+	googleAdsService := newGoogleAdsService(c.Query("manager_id"))
 
 	// Listar campanhas
-	campaigns, err := googleAdsService.ListCampaigns(clientID, clientSecret, refreshToken, accountID)
+	campaigns, err := googleAdsService.ListCampaigns(clientID, clientSecret, refreshToken, accountID, opts)
 	if err != nil {
 		// Extrair informações detalhadas do erro
 		errorInfo := extractErrorInfo(err)
@@ -807,6 +1792,425 @@ func getGoogleAdsCampaigns(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// @Summary Listar grupos de anúncios de uma campanha do Google Ads
+// @Description Lista os grupos de anúncios da campanha com status e métricas do período, calculadas como na listagem de campanhas
+// @Tags Google Ads
+// @Produce json
+// @Param campaign_id path string true "ID da campanha"
+// @Param account_id query string true "ID da conta de anúncios dona da campanha"
+// @Param client_id query string true "ID do cliente OAuth"
+// @Param client_secret query string true "Secret do cliente OAuth"
+// @Param refresh_token query string true "Token de atualização OAuth"
+// @Param manager_id query string false "ID da conta gerenciadora (MCC), enviado como login-customer-id"
+// @Param date_range query string false "Período predefinido do GAQL (ex: LAST_7_DAYS, LAST_MONTH); padrão LAST_30_DAYS"
+// @Param since query string false "Data inicial (AAAA-MM-DD); exige until"
+// @Param until query string false "Data final (AAAA-MM-DD); exige since"
+// @Param segment query string false "date, week ou month; cada grupo tem uma linha por período"
+// @Param conversion_actions query string false "IDs das ações de conversão contadas, separados por vírgula; padrão: as incluídas em conversões na conta"
+// @Success 200 {object} models.GoogleAdsAdGroupListResponse
+// @Failure 400 {object} models.GoogleAdsAdGroupListResponse
+// @Failure 500 {object} models.GoogleAdsAdGroupListResponse
+// @Router /google-ads/campanha/{campaign_id}/grupos [get]
+func getGoogleAdsAdGroups(c *gin.Context) {
+	campaignID := c.Param("campaign_id")
+	accountID, clientID, clientSecret, refreshToken, ok := googleAdsReportCredentials(c)
+	if !ok {
+		return
+	}
+
+	opts := googleAdsReportOptionsFromQuery(c)
+	if respondGoogleInvalidOptions(c, opts) || respondGoogleUnsupportedBreakdowns(c, opts) {
+		return
+	}
+
+	googleAdsService := newGoogleAdsService(c.Query("manager_id"))
+
+	adGroups, err := googleAdsService.ListAdGroups(clientID, clientSecret, refreshToken, accountID, campaignID, opts)
+	if err != nil {
+		status, errorInfo := googleAdsErrorInfo(err)
+		c.JSON(status, models.GoogleAdsAdGroupListResponse{
+			Success: false,
+			Message: "Erro ao listar grupos de anúncios",
+			Error:   errorInfo,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.GoogleAdsAdGroupListResponse{
+		Success: true,
+		Message: "Grupos de anúncios listados com sucesso",
+		Data:    adGroups,
+	})
+}
+
+// @Summary Listar anúncios do Google Ads
+// @Description Lista os anúncios responsivos de pesquisa de uma campanha ou de um grupo de anúncios com títulos, descrições, status e métricas do período
+// @Tags Google Ads
+// @Produce json
+// @Param campaign_id path string false "ID da campanha"
+// @Param ad_group_id path string false "ID do grupo de anúncios"
+// @Param account_id query string true "ID da conta de anúncios dona da campanha"
+// @Param client_id query string true "ID do cliente OAuth"
+// @Param client_secret query string true "Secret do cliente OAuth"
+// @Param refresh_token query string true "Token de atualização OAuth"
+// @Param manager_id query string false "ID da conta gerenciadora (MCC), enviado como login-customer-id"
+// @Param date_range query string false "Período predefinido do GAQL (ex: LAST_7_DAYS, LAST_MONTH); padrão LAST_30_DAYS"
+// @Param since query string false "Data inicial (AAAA-MM-DD); exige until"
+// @Param until query string false "Data final (AAAA-MM-DD); exige since"
+// @Param segment query string false "date, week ou month; cada anúncio tem uma linha por período"
+// @Param conversion_actions query string false "IDs das ações de conversão contadas, separados por vírgula; padrão: as incluídas em conversões na conta"
+// @Success 200 {object} models.GoogleAdsAdListResponse
+// @Failure 400 {object} models.GoogleAdsAdListResponse
+// @Failure 500 {object} models.GoogleAdsAdListResponse
+// @Router /google-ads/campanha/{campaign_id}/anuncios [get]
+// @Router /google-ads/grupo/{ad_group_id}/anuncios [get]
+func getGoogleAdsAds(c *gin.Context) {
+	accountID, clientID, clientSecret, refreshToken, ok := googleAdsReportCredentials(c)
+	if !ok {
+		return
+	}
+
+	opts := googleAdsReportOptionsFromQuery(c)
+	if respondGoogleInvalidOptions(c, opts) || respondGoogleUnsupportedBreakdowns(c, opts) {
+		return
+	}
+
+	googleAdsService := newGoogleAdsService(c.Query("manager_id"))
+
+	ads, err := googleAdsService.ListAds(clientID, clientSecret, refreshToken, accountID, c.Param("campaign_id"), c.Param("ad_group_id"), opts)
+	if err != nil {
+		status, errorInfo := googleAdsErrorInfo(err)
+		c.JSON(status, models.GoogleAdsAdListResponse{
+			Success: false,
+			Message: "Erro ao listar anúncios",
+			Error:   errorInfo,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.GoogleAdsAdListResponse{
+		Success: true,
+		Message: "Anúncios listados com sucesso",
+		Data:    ads,
+	})
+}
+
+// @Summary Listar palavras-chave do Google Ads
+// @Description Lista as palavras-chave de uma campanha ou de um grupo de anúncios com tipo de correspondência, status, índice de qualidade e métricas do período
+// @Tags Google Ads
+// @Produce json
+// @Param campaign_id path string false "ID da campanha"
+// @Param ad_group_id path string false "ID do grupo de anúncios"
+// @Param account_id query string true "ID da conta de anúncios dona da campanha"
+// @Param client_id query string true "ID do cliente OAuth"
+// @Param client_secret query string true "Secret do cliente OAuth"
+// @Param refresh_token query string true "Token de atualização OAuth"
+// @Param manager_id query string false "ID da conta gerenciadora (MCC), enviado como login-customer-id"
+// @Param date_range query string false "Período predefinido do GAQL (ex: LAST_7_DAYS, LAST_MONTH); padrão LAST_30_DAYS"
+// @Param since query string false "Data inicial (AAAA-MM-DD); exige until"
+// @Param until query string false "Data final (AAAA-MM-DD); exige since"
+// @Param segment query string false "date, week ou month; cada palavra-chave tem uma linha por período"
+// @Param conversion_actions query string false "IDs das ações de conversão contadas, separados por vírgula; padrão: as incluídas em conversões na conta"
+// @Success 200 {object} models.GoogleAdsKeywordListResponse
+// @Failure 400 {object} models.GoogleAdsKeywordListResponse
+// @Failure 500 {object} models.GoogleAdsKeywordListResponse
+// @Router /google-ads/campanha/{campaign_id}/palavras-chave [get]
+// @Router /google-ads/grupo/{ad_group_id}/palavras-chave [get]
+func getGoogleAdsKeywords(c *gin.Context) {
+	accountID, clientID, clientSecret, refreshToken, ok := googleAdsReportCredentials(c)
+	if !ok {
+		return
+	}
+
+	opts := googleAdsReportOptionsFromQuery(c)
+	if respondGoogleInvalidOptions(c, opts) || respondGoogleUnsupportedBreakdowns(c, opts) {
+		return
+	}
+
+	googleAdsService := newGoogleAdsService(c.Query("manager_id"))
+
+	keywords, err := googleAdsService.ListKeywords(clientID, clientSecret, refreshToken, accountID, c.Param("campaign_id"), c.Param("ad_group_id"), opts)
+	if err != nil {
+		status, errorInfo := googleAdsErrorInfo(err)
+		c.JSON(status, models.GoogleAdsKeywordListResponse{
+			Success: false,
+			Message: "Erro ao listar palavras-chave",
+			Error:   errorInfo,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.GoogleAdsKeywordListResponse{
+		Success: true,
+		Message: "Palavras-chave listadas com sucesso",
+		Data:    keywords,
+	})
+}
+
+// @Summary Relatório de termos de pesquisa do Google Ads
+// @Description Lista os termos de pesquisa que acionaram anúncios, com a palavra-chave correspondente e impressões, cliques, custo e conversões do período, do maior para o menor custo
+// @Tags Google Ads
+// @Produce json
+// @Param account_id query string true "ID da conta de anúncios"
+// @Param campaign_id query string false "ID da campanha; sem ele, são listados os termos de todas as campanhas da conta"
+// @Param client_id query string true "ID do cliente OAuth"
+// @Param client_secret query string true "Secret do cliente OAuth"
+// @Param refresh_token query string true "Token de atualização OAuth"
+// @Param manager_id query string false "ID da conta gerenciadora (MCC), enviado como login-customer-id"
+// @Param date_range query string false "Período predefinido do GAQL (ex: LAST_7_DAYS, LAST_MONTH); padrão LAST_30_DAYS"
+// @Param since query string false "Data inicial (AAAA-MM-DD); exige until"
+// @Param until query string false "Data final (AAAA-MM-DD); exige since"
+// @Param segment query string false "date, week ou month; cada termo tem uma linha por período"
+// @Param conversion_actions query string false "IDs das ações de conversão contadas, separados por vírgula; padrão: as incluídas em conversões na conta"
+// @Success 200 {object} models.GoogleAdsSearchTermListResponse
+// @Failure 400 {object} models.GoogleAdsSearchTermListResponse
+// @Failure 500 {object} models.GoogleAdsSearchTermListResponse
+// @Router /google-ads/termos-pesquisa [get]
+func getGoogleAdsSearchTerms(c *gin.Context) {
+	terms, ok := googleAdsSearchTerms(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.GoogleAdsSearchTermListResponse{
+		Success: true,
+		Message: "Termos de pesquisa listados com sucesso",
+		Data:    terms,
+	})
+}
+
+// @Summary Exportar termos de pesquisa do Google Ads em CSV
+// @Description Exporta o relatório de termos de pesquisa em CSV (separado por vírgulas, UTF-8), com os mesmos filtros do endpoint JSON
+// @Tags Google Ads
+// @Produce text/csv
+// @Param account_id query string true "ID da conta de anúncios"
+// @Param campaign_id query string false "ID da campanha; sem ele, são listados os termos de todas as campanhas da conta"
+// @Param client_id query string true "ID do cliente OAuth"
+// @Param client_secret query string true "Secret do cliente OAuth"
+// @Param refresh_token query string true "Token de atualização OAuth"
+// @Param manager_id query string false "ID da conta gerenciadora (MCC), enviado como login-customer-id"
+// @Param date_range query string false "Período predefinido do GAQL (ex: LAST_7_DAYS, LAST_MONTH); padrão LAST_30_DAYS"
+// @Param since query string false "Data inicial (AAAA-MM-DD); exige until"
+// @Param until query string false "Data final (AAAA-MM-DD); exige since"
+// @Param segment query string false "date, week ou month; cada termo tem uma linha por período"
+// @Param conversion_actions query string false "IDs das ações de conversão contadas, separados por vírgula; padrão: as incluídas em conversões na conta"
+// @Success 200 {string} string "Arquivo CSV"
+// @Failure 400 {object} models.GoogleAdsSearchTermListResponse
+// @Failure 500 {object} models.GoogleAdsSearchTermListResponse
+// @Router /google-ads/termos-pesquisa/csv [get]
+func exportGoogleAdsSearchTermsCSV(c *gin.Context) {
+	terms, ok := googleAdsSearchTerms(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="termos-pesquisa.csv"`)
+	c.Status(http.StatusOK)
+	if err := services.WriteSearchTermsCSV(c.Writer, terms); err != nil {
+		log.Printf("Erro ao escrever o CSV de termos de pesquisa: %v", err)
+	}
+}
+
+// googleAdsSearchTerms obtém o relatório de termos de pesquisa com os filtros da query, respondendo com erro se falhar
+func googleAdsSearchTerms(c *gin.Context) ([]models.GoogleAdsSearchTermData, bool) {
+	accountID, clientID, clientSecret, refreshToken, ok := googleAdsReportCredentials(c)
+	if !ok {
+		return nil, false
+	}
+
+	opts := googleAdsReportOptionsFromQuery(c)
+	if respondGoogleInvalidOptions(c, opts) || respondGoogleUnsupportedBreakdowns(c, opts) {
+		return nil, false
+	}
+
+	googleAdsService := newGoogleAdsService(c.Query("manager_id"))
+
+	terms, err := googleAdsService.ListSearchTerms(clientID, clientSecret, refreshToken, accountID, c.Query("campaign_id"), opts)
+	if err != nil {
+		status, errorInfo := googleAdsErrorInfo(err)
+		c.JSON(status, models.GoogleAdsSearchTermListResponse{
+			Success: false,
+			Message: "Erro ao obter termos de pesquisa",
+			Error:   errorInfo,
+		})
+		return nil, false
+	}
+
+	return terms, true
+}
+
+// googleAdsReportCredentials extrai account_id e as credenciais OAuth da query dos relatórios do Google Ads,
+// respondendo com erro 400 se algum estiver ausente
+func googleAdsReportCredentials(c *gin.Context) (accountID, clientID, clientSecret, refreshToken string, ok bool) {
+	accountID = c.Query("account_id")
+	clientID = c.Query("client_id")
+	clientSecret = c.Query("client_secret")
+	refreshToken = c.Query("refresh_token")
+
+	if accountID == "" {
+		respondWithError(c, http.StatusBadRequest, "ID da conta (account_id) não fornecido")
+		return "", "", "", "", false
+	}
+
+	if clientID == "" || clientSecret == "" || refreshToken == "" {
+		respondWithError(c, http.StatusBadRequest, "Credenciais incompletas")
+		return "", "", "", "", false
+	}
+
+	return accountID, clientID, clientSecret, refreshToken, true
+}
+
+// googleAdsErrorInfo retorna o status HTTP e os detalhes de um erro do Google Ads; consultas inválidas retornam 400
+func googleAdsErrorInfo(err error) (int, *models.ErrorInfo) {
+	errorInfo := extractErrorInfo(err)
+	if errors.Is(err, services.ErrInvalidGAQLQuery) {
+		errorInfo.Type = "Validation Error"
+		return http.StatusBadRequest, errorInfo
+	}
+	return http.StatusInternalServerError, errorInfo
+}
+
+// googleAdsReportOptionsFromQuery extrai o período, a segmentação, os breakdowns e as ações de conversão das consultas do Google Ads dos parâmetros da query
+func googleAdsReportOptionsFromQuery(c *gin.Context) services.GoogleAdsReportOptions {
+	return services.GoogleAdsReportOptions{
+		DateRange: c.Query("date_range"),
+		Since:     c.Query("since"),
+		Until:     c.Query("until"),
+		Segment:   c.Query("segment"),
+
+		Breakdowns:        splitQueryList(c.Query("breakdowns")),
+		ConversionActions: splitQueryList(c.Query("conversion_actions")),
+	}
+}
+
+// googleAdsReportOptionsFromRequest extrai o período, a segmentação e as ações de conversão das consultas do Google Ads do corpo da requisição
+func googleAdsReportOptionsFromRequest(request models.GoogleAdsRequest) services.GoogleAdsReportOptions {
+	return services.GoogleAdsReportOptions{
+		DateRange: request.DateRange,
+		Since:     request.Since,
+		Until:     request.Until,
+		Segment:   request.Segment,
+
+		ConversionActions: request.ConversionActions,
+	}
+}
+
+// respondGoogleInvalidOptions valida o período e a segmentação e responde com erro 400 se forem inválidos
+func respondGoogleInvalidOptions(c *gin.Context, opts services.GoogleAdsReportOptions) bool {
+	if err := opts.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, models.GoogleAdsResponse{
+			Success: false,
+			Message: "Parâmetros de consulta inválidos",
+			Error:   &models.ErrorInfo{Message: err.Error(), Type: "Validation Error"},
+		})
+		return true
+	}
+	return false
+}
+
+// respondGoogleUnsupportedBreakdowns responde com erro 400 quando breakdowns é informado em um endpoint que não
+// segmenta as linhas por breakdowns (apenas os insights de campanha e de conta os aceitam)
+func respondGoogleUnsupportedBreakdowns(c *gin.Context, opts services.GoogleAdsReportOptions) bool {
+	if opts.HasBreakdowns() {
+		c.JSON(http.StatusBadRequest, models.GoogleAdsResponse{
+			Success: false,
+			Message: "Parâmetros de consulta inválidos",
+			Error: &models.ErrorInfo{
+				Message: "breakdowns só é aceito em /google-ads/campanha/{campaign_id} e /google-ads/conta/{account_id}",
+				Type:    "Validation Error",
+			},
+		})
+		return true
+	}
+	return false
+}
+
+// respondGoogleAdsSeriesError responde com o erro de uma série temporal do Google Ads; consultas inválidas retornam 400
+func respondGoogleAdsSeriesError(c *gin.Context, message string, err error) {
+	status, errorInfo := googleAdsErrorInfo(err)
+	c.JSON(status, models.GoogleAdsSeriesResponse{
+		Success: false,
+		Message: message,
+		Error:   errorInfo,
+	})
+}
+
+// metaInsightsOptionsFromQuery extrai as opções de consulta de insights do Meta Ads dos parâmetros da query
+func metaInsightsOptionsFromQuery(c *gin.Context) services.MetaInsightsOptions {
+	return services.MetaInsightsOptions{
+		ActionTypes:   splitQueryList(c.Query("action_types")),
+		DatePreset:    c.Query("date_preset"),
+		Since:         c.Query("since"),
+		Until:         c.Query("until"),
+		TimeIncrement: c.Query("time_increment"),
+
+		Breakdowns:       splitQueryList(c.Query("breakdowns")),
+		ActionBreakdowns: splitQueryList(c.Query("action_breakdowns")),
+	}
+}
+
+// respondMetaInvalidOptions valida as opções de insights e responde com erro 400 se forem inválidas
+func respondMetaInvalidOptions(c *gin.Context, opts services.MetaInsightsOptions) bool {
+	if err := opts.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, models.MetaAdsResponse{
+			Success: false,
+			Message: "Parâmetros de consulta inválidos",
+			Error:   &models.ErrorInfo{Message: err.Error(), Type: "Validation Error"},
+		})
+		return true
+	}
+	return false
+}
+
+// respondMetaUnsupportedOptions responde com erro 400 quando time_increment, breakdowns ou action_breakdowns são
+// informados em um endpoint que retorna apenas o agregado do período (apenas os insights de campanha e de conta
+// e os relatórios assíncronos retornam séries e linhas segmentadas)
+func respondMetaUnsupportedOptions(c *gin.Context, opts services.MetaInsightsOptions) bool {
+	if opts.TimeIncrement != "" || opts.HasBreakdowns() || len(opts.ActionBreakdowns) > 0 {
+		c.JSON(http.StatusBadRequest, models.MetaAdsResponse{
+			Success: false,
+			Message: "Parâmetros de consulta inválidos",
+			Error: &models.ErrorInfo{
+				Message: "time_increment, breakdowns e action_breakdowns só são aceitos em /meta-ads/campanha/{campaign_id}, /meta-ads/conta/{account_id} e /meta-ads/relatorios",
+				Type:    "Validation Error",
+			},
+		})
+		return true
+	}
+	return false
+}
+
+// splitQueryList separa um parâmetro de query com valores separados por vírgula
+func splitQueryList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// newGoogleAdsService cria o serviço do Google Ads; a conta gerenciadora (MCC), se informada,
+// é enviada como login-customer-id em todas as chamadas à API
+func newGoogleAdsService(managerID string) *services.GoogleAdsService {
+	googleAdsService := services.NewGoogleAdsService()
+	googleAdsService.Config.ManagerID = managerID
+	return googleAdsService
+}
+
+// newMetaAdsService cria o serviço do Meta Ads com as configurações do aplicativo, para que as
+// chamadas à Graph API levem o appsecret_proof quando META_APP_SECRET estiver definido
+func newMetaAdsService() *services.MetaAdsService {
+	return services.NewMetaAdsServiceWithConfig(
+		metaAppID,
+		metaAppSecret,
+		metaRedirectURI,
+		metaState,
+	)
+}
+
 func respondWithError(c *gin.Context, code int, message string) {
 	response := models.HotmartResponse{
 		Status:  "error",
@@ -893,7 +2297,7 @@ func handleMetaAdsAuth(c *gin.Context) {
 }
 
 // @Summary Callback da autenticação OAuth do Meta Ads
-// @Description Recebe o código de autorização e o troca por um token de acesso
+// @Description Recebe o código de autorização e o troca por um token de acesso de longa duração (cerca de 60 dias)
 // @Tags Meta Ads
 // @Produce json
 // @Param code query string true "Código de autorização"
@@ -954,10 +2358,129 @@ func handleMetaAdsCallback(c *gin.Context) {
 		return
 	}
 
+	// Trocar o token de curta duração (algumas horas) por um de longa duração (cerca de 60 dias)
+	longLivedToken, err := metaService.ExchangeForLongLivedToken(tokenResponse.AccessToken)
+	if err != nil {
+		log.Printf("Não foi possível obter o token de longa duração do Meta Ads, retornando o token de curta duração: %v", err)
+	} else {
+		tokenResponse = longLivedToken
+	}
+
 	// Retornar o token de acesso
 	c.JSON(http.StatusOK, tokenResponse)
 }
 
+// Endpoint para inspecionar um token do Meta Ads
+// @Summary Inspecionar token do Meta Ads
+// @Description Consulta o debug_token do Meta e retorna validade, escopos, expiração, aplicativo e usuário do token, indicando se ele precisa ser renovado
+// @Tags Meta Ads
+// @Produce json
+// @Param token query string true "Token de acesso do Meta Ads"
+// @Success 200 {object} models.MetaTokenInfoResponse
+// @Failure 400 {object} models.MetaTokenInfoResponse
+// @Failure 500 {object} models.MetaTokenInfoResponse
+// @Router /meta-ads/token [get]
+func getMetaAdsTokenInfo(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, models.MetaTokenInfoResponse{
+			Success: false,
+			Message: "Token é obrigatório",
+			Error:   &models.ErrorInfo{Message: "token é obrigatório", Type: "Validation Error"},
+		})
+		return
+	}
+
+	metaService := newMetaAdsService()
+
+	info, err := metaService.InspectToken(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.MetaTokenInfoResponse{
+			Success: false,
+			Message: "Erro ao inspecionar token",
+			Error:   extractErrorInfo(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MetaTokenInfoResponse{
+		Success: true,
+		Message: "Token inspecionado com sucesso",
+		Data:    info,
+	})
+}
+
+// Endpoint para cadastrar os tokens das páginas usados na leitura dos leads
+// @Summary Cadastrar páginas do Meta para Lead Ads
+// @Description Obtém as páginas administradas pelo usuário (/me/accounts) e armazena seus tokens, usados para ler os leads recebidos em /webhook/meta
+// @Tags Meta Ads
+// @Accept json
+// @Produce json
+// @Param request body models.MetaPageRequest true "Token do usuário com pages_show_list e leads_retrieval"
+// @Success 200 {object} models.MetaPageListResponse
+// @Failure 400 {object} models.MetaPageListResponse
+// @Failure 500 {object} models.MetaPageListResponse
+// @Router /meta-ads/paginas [post]
+func registerMetaAdsPages(c *gin.Context) {
+	var request models.MetaPageRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.MetaPageListResponse{
+			Success: false,
+			Message: "Token é obrigatório",
+			Error:   &models.ErrorInfo{Message: err.Error(), Type: "Validation Error"},
+		})
+		return
+	}
+
+	metaAdsService := newMetaAdsService()
+
+	pages, err := metaAdsService.SyncPageTokens(metaPageTokens, request.Token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.MetaPageListResponse{
+			Success: false,
+			Message: "Erro ao cadastrar páginas",
+			Error:   extractErrorInfo(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MetaPageListResponse{
+		Success: true,
+		Message: "Páginas cadastradas com sucesso",
+		Data:    pages,
+	})
+}
+
+// Endpoint para listar as páginas cadastradas para Lead Ads
+// @Summary Listar páginas do Meta cadastradas
+// @Description Lista as páginas cujos tokens estão cadastrados para a leitura dos leads
+// @Tags Meta Ads
+// @Produce json
+// @Success 200 {object} models.MetaPageListResponse
+// @Router /meta-ads/paginas [get]
+func listMetaAdsPages(c *gin.Context) {
+	c.JSON(http.StatusOK, models.MetaPageListResponse{
+		Success: true,
+		Message: "Páginas listadas com sucesso",
+		Data:    metaPageTokens.List(),
+	})
+}
+
+// Endpoint para listar os leads recebidos pelo webhook do Meta
+// @Summary Listar leads do Meta
+// @Description Lista os leads recebidos pelo webhook do Meta, com os dados do formulário e a campanha de origem
+// @Tags Meta Ads
+// @Produce json
+// @Success 200 {object} models.MetaLeadListResponse
+// @Router /meta-ads/leads [get]
+func listMetaAdsLeads(c *gin.Context) {
+	c.JSON(http.StatusOK, models.MetaLeadListResponse{
+		Success: true,
+		Message: "Leads listados com sucesso",
+		Data:    eventStore.ListByType(models.EventoLead),
+	})
+}
+
 // @Summary Iniciar autenticação OAuth do Google Ads
 // @Description Redireciona o usuário para a página de autorização do Google
 // @Tags Google Ads
@@ -1130,6 +2653,7 @@ func testGoogleConnection(c *gin.Context) {
 // @Param client_id query string true "ID do cliente OAuth"
 // @Param client_secret query string true "Secret do cliente OAuth"
 // @Param refresh_token query string true "Token de atualização OAuth"
+// @Param manager_id query string false "ID da conta gerenciadora (MCC), enviado como login-customer-id"
 // @Success 200 {object} models.GoogleAdsAccountInfoResponse
 // @Failure 400 {object} models.GoogleAdsAccountInfoResponse
 // @Failure 500 {object} models.GoogleAdsAccountInfoResponse
@@ -1153,7 +2677,7 @@ func getGoogleAdsAccountInfo(c *gin.Context) {
 	}
 
 	// Criar serviço do Google Ads
-	googleAdsService := services.NewGoogleAdsService()
+	googleAdsService := newGoogleAdsService(c.Query("manager_id"))
 
 	// Obter informações básicas da conta
 	accountInfo, err := googleAdsService.GetAccountBasicInfo(clientID, clientSecret, refreshToken, accountID)
@@ -1184,23 +2708,26 @@ func getGoogleAdsAccountInfo(c *gin.Context) {
 
 // getGoogleAdsConsolidatedData godoc
 // @Summary Dados consolidados de todas as campanhas e contas do Google Ads
-// @Description Consulta dados consolidados do Google Ads usando as credenciais fornecidas na requisição. Retorna uma lista com métricas de todas as campanhas de todas as contas que o usuário tem acesso.
+// @Description Consulta dados consolidados do Google Ads usando as credenciais fornecidas na requisição. Percorre a hierarquia de contas (a partir de manager_id ou de todas as contas acessíveis) e retorna, para cada conta cliente, uma linha da conta seguida das suas campanhas, com nome e moeda da conta. Contas que falharem são listadas em "erros".
 // @Tags Google Ads
 // @Accept json
 // @Produce json
-// @Param request body models.GoogleAdsRequest true "Credenciais de acesso do Google Ads"
+// @Param request body models.GoogleAdsRequest true "Credenciais de acesso do Google Ads, período (date_range ou since/until), segment e conversion_actions opcionais"
 // @Success 200 {object} map[string]interface{} "Lista de métricas consolidadas"
 // @Failure 400 {object} models.GoogleAdsResponse "Erro na requisição"
 // @Failure 500 {object} models.GoogleAdsResponse "Erro interno do servidor"
 // @Router /api/google-ads/consolidated [post]
 func getGoogleAdsConsolidatedData(c *gin.Context) {
-	var clientID, clientSecret, refreshToken string
+	var clientID, clientSecret, refreshToken, managerID string
+	var opts services.GoogleAdsReportOptions
 
 	if c.Request.Method == "GET" {
 		// Para GET, extrair parâmetros da query string
 		clientID = c.Query("client_id")
 		clientSecret = c.Query("client_secret")
 		refreshToken = c.Query("refresh_token")
+		managerID = c.Query("manager_id")
+		opts = googleAdsReportOptionsFromQuery(c)
 
 		if clientID == "" || clientSecret == "" || refreshToken == "" {
 			c.JSON(http.StatusBadRequest, gin.H{
@@ -1225,12 +2752,18 @@ func getGoogleAdsConsolidatedData(c *gin.Context) {
 		clientID = request.ClientID
 		clientSecret = request.ClientSecret
 		refreshToken = request.RefreshToken
+		managerID = request.ManagerID
+		opts = googleAdsReportOptionsFromRequest(request)
+	}
+
+	if respondGoogleInvalidOptions(c, opts) || respondGoogleUnsupportedBreakdowns(c, opts) {
+		return
 	}
 
 	log.Printf("Iniciando busca de dados consolidados do Google Ads com client_id: %s...\n", clientID)
-	googleAdsService := services.NewGoogleAdsService()
+	googleAdsService := newGoogleAdsService(managerID)
 
-	data, err := googleAdsService.GetConsolidatedCampaignData(clientID, clientSecret, refreshToken)
+	data, itemErrors, err := googleAdsService.GetConsolidatedCampaignData(clientID, clientSecret, refreshToken, opts)
 	if err != nil {
 		log.Printf("Erro ao obter dados consolidados do Google Ads: %v\n", err)
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -1241,18 +2774,24 @@ func getGoogleAdsConsolidatedData(c *gin.Context) {
 		return
 	}
 
-	log.Printf("Dados consolidados do Google Ads obtidos com sucesso. Total de itens: %d\n", len(data))
+	log.Printf("Dados consolidados do Google Ads obtidos. Total de itens: %d, contas com erro: %d\n", len(data), len(itemErrors))
 
 	// Se a lista estiver vazia, retornar lista vazia
 	if len(data) == 0 {
 		log.Println("Lista de dados vazia.")
 	}
 
+	message := "Dados consolidados obtidos com sucesso"
+	if len(itemErrors) > 0 {
+		message = "Dados consolidados obtidos parcialmente; veja as contas com erro"
+	}
+
 	// Return the actual data
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "Dados consolidados obtidos com sucesso",
+		"message": message,
 		"data":    data,
+		"erros":   itemErrors,
 	})
 }
 
@@ -1263,12 +2802,16 @@ func getGoogleAdsConsolidatedData(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param request body models.MetaAdsRequest true "Token de acesso do Meta Ads"
+// @Param date_preset query string false "Período predefinido do Meta (ex: last_7d, last_month, maximum); padrão last_30d"
+// @Param since query string false "Data inicial (AAAA-MM-DD); exige until"
+// @Param until query string false "Data final (AAAA-MM-DD); exige since"
 // @Success 200 {object} map[string]interface{} "Lista de métricas consolidadas"
 // @Failure 400 {object} models.MetaAdsResponse "Erro na requisição"
 // @Failure 500 {object} models.MetaAdsResponse "Erro interno do servidor"
 // @Router /api/meta-ads/consolidated [post]
 func getMetaAdsConsolidatedData(c *gin.Context) {
 	var token string
+	opts := metaInsightsOptionsFromQuery(c)
 
 	if c.Request.Method == "GET" {
 		token = c.Query("token")
@@ -1291,12 +2834,19 @@ func getMetaAdsConsolidatedData(c *gin.Context) {
 			return
 		}
 		token = request.Token
+		if len(request.ActionTypes) > 0 {
+			opts.ActionTypes = request.ActionTypes
+		}
+	}
+
+	if respondMetaInvalidOptions(c, opts) || respondMetaUnsupportedOptions(c, opts) {
+		return
 	}
 
 	log.Printf("Iniciando busca de dados consolidados com token: %s...\n", token[:10])
-	metaAdsService := services.NewMetaAdsService()
+	metaAdsService := newMetaAdsService()
 
-	data, err := metaAdsService.GetConsolidatedCampaignData(token)
+	data, itemErrors, err := metaAdsService.GetConsolidatedCampaignData(token, opts)
 	if err != nil {
 		log.Printf("Erro ao obter dados consolidados: %v\n", err)
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -1307,17 +2857,23 @@ func getMetaAdsConsolidatedData(c *gin.Context) {
 		return
 	}
 
-	log.Printf("Dados consolidados obtidos com sucesso. Total de itens: %d\n", len(data))
+	log.Printf("Dados consolidados obtidos. Total de itens: %d, itens com erro: %d\n", len(data), len(itemErrors))
 
 	// Se a lista estiver vazia, retornar lista vazia
 	if len(data) == 0 {
 		log.Println("Lista de dados vazia.")
 	}
 
+	message := "Dados consolidados obtidos com sucesso"
+	if len(itemErrors) > 0 {
+		message = "Dados consolidados obtidos parcialmente; veja os itens com erro"
+	}
+
 	// Return the actual data
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "Dados consolidados obtidos com sucesso",
+		"message": message,
 		"data":    data,
+		"erros":   itemErrors,
 	})
 }
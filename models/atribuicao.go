@@ -0,0 +1,20 @@
+package models
+
+// AttributionResult contém as conversões e a receita atribuídas a uma campanha
+type AttributionResult struct {
+	UTMSource   string  `json:"utm_source"`   // Origem do tráfego
+	UTMMedium   string  `json:"utm_medium"`   // Meio do tráfego
+	UTMCampaign string  `json:"utm_campaign"` // Campanha
+	Conversoes  float64 `json:"conversoes"`   // Conversões atribuídas (fracionárias em modelos multi-toque)
+	Receita     float64 `json:"receita"`      // Receita atribuída
+	Toques      int     `json:"toques"`       // Número de pontos de contato que participaram das conversões
+}
+
+// AttributionResponse representa a resposta do relatório de atribuição
+type AttributionResponse struct {
+	Success bool                `json:"success"`          // Indica se a operação foi bem-sucedida
+	Message string              `json:"message"`          // Mensagem descritiva
+	Modelo  string              `json:"modelo,omitempty"` // Modelo de atribuição utilizado
+	Data    []AttributionResult `json:"data,omitempty"`   // Resultados por campanha
+	Error   *ErrorInfo          `json:"error,omitempty"`  // Informações de erro, se houver
+}
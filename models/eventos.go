@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Tipos de evento de checkout normalizados a partir dos webhooks
+const (
+	EventoCompra             = "compra"
+	EventoCarrinhoAbandonado = "carrinho_abandonado"
+	EventoReembolso          = "reembolso"
+	EventoRecusado           = "recusado"
+	EventoOutro              = "outro"
+	EventoLead               = "lead"
+)
+
+// CheckoutEvent representa um evento de checkout normalizado recebido de qualquer plataforma (Kiwify, Hotmart, Kirvano)
+// ou um lead recebido dos formulários de Lead Ads do Meta
+type CheckoutEvent struct {
+	ID          string    `json:"id"`                     // ID do pedido/transação/lead na plataforma de origem
+	Plataforma  string    `json:"plataforma"`             // kiwify, hotmart, kirvano ou meta
+	Tipo        string    `json:"tipo"`                   // compra, carrinho_abandonado, reembolso, recusado, lead ou outro
+	Email       string    `json:"email"`                  // E-mail do cliente, normalizado em minúsculas
+	Nome        string    `json:"nome,omitempty"`         // Nome do cliente
+	Telefone    string    `json:"telefone,omitempty"`     // Telefone do cliente
+	ProdutoID   string    `json:"produto_id,omitempty"`   // ID do produto principal
+	ProdutoNome string    `json:"produto_nome,omitempty"` // Nome do produto principal
+	Valor       float64   `json:"valor"`                  // Valor do pedido
+	Moeda       string    `json:"moeda,omitempty"`        // Código da moeda (ex: BRL)
+	UTMSource   string    `json:"utm_source,omitempty"`   // Origem do tráfego
+	UTMMedium   string    `json:"utm_medium,omitempty"`   // Meio do tráfego
+	UTMCampaign string    `json:"utm_campaign,omitempty"` // Campanha
+	UTMContent  string    `json:"utm_content,omitempty"`  // Conteúdo
+	UTMTerm     string    `json:"utm_term,omitempty"`     // Termo
+	DataEvento  time.Time `json:"data_evento"`            // Data em que o evento ocorreu
+
+	Campos map[string]string `json:"campos,omitempty"` // Respostas do formulário de lead, por nome do campo
+}
+
+// HasUTM indica se o evento possui informações de campanha suficientes para atribuição
+func (e CheckoutEvent) HasUTM() bool {
+	return e.UTMSource != "" || e.UTMCampaign != ""
+}
@@ -6,6 +6,13 @@ type GoogleAdsRequest struct {
 	ClientSecret string `json:"client_secret" binding:"required"` // Secret do cliente OAuth
 	RefreshToken string `json:"refresh_token" binding:"required"` // Token de atualização OAuth
 	ManagerID    string `json:"manager_id,omitempty"`              // ID da conta gerenciadora (opcional)
+	AccountID    string `json:"account_id,omitempty"`              // Conta cliente das métricas; obrigatória quando há mais de uma
+	DateRange    string `json:"date_range,omitempty"`              // Período predefinido do GAQL (padrão LAST_30_DAYS)
+	Since        string `json:"since,omitempty"`                   // Data inicial (AAAA-MM-DD)
+	Until        string `json:"until,omitempty"`                   // Data final (AAAA-MM-DD)
+	Segment      string `json:"segment,omitempty"`                 // date, week ou month para uma série temporal
+
+	ConversionActions []string `json:"conversion_actions,omitempty"` // IDs das ações de conversão contadas (padrão: as incluídas em conversões na conta)
 }
 
 // GoogleAdsData contém as métricas do Google Ads
@@ -20,6 +27,26 @@ type GoogleAdsData struct {
 	InvestimentoTotal float64 `json:"investimento_total"`          // Investimento Total
 	Impressions       int     `json:"impressions"`                 // Número de impressões
 	Clicks            int     `json:"clicks"`                      // Número de cliques
+	Tipo              string  `json:"tipo,omitempty"`              // conta ou campanha (dados consolidados)
+	ContaID           string  `json:"conta_id,omitempty"`          // ID da conta a que os dados pertencem
+	ContaNome         string  `json:"conta_nome,omitempty"`        // Nome da conta
+	Moeda             string  `json:"moeda,omitempty"`             // Código da moeda da conta (ex: BRL, USD)
+	Periodo           string  `json:"periodo,omitempty"`           // Início do período (AAAA-MM-DD) com segmentação por date, week ou month
+
+	ValorConversoes         float64  `json:"valor_conversoes"`                    // Valor das conversões
+	TodasConversoes         int      `json:"todas_conversoes"`                    // Todas as conversões, incluindo as não contadas em conversoes
+	ValorPorConversao       float64  `json:"valor_por_conversao"`                 // Valor médio por conversão
+	ROAS                    float64  `json:"roas"`                                // Retorno sobre o investimento em anúncios (valor das conversões / investimento)
+	ParcelaImpressoes       *float64 `json:"parcela_impressoes,omitempty"`        // Parcela de impressões de pesquisa (%); apenas contas e campanhas
+	ParcelaPerdidaOrcamento *float64 `json:"parcela_perdida_orcamento,omitempty"` // Parcela de impressões de pesquisa perdida por orçamento (%)
+}
+
+// GoogleAdsItemError descreve uma conta cujos dados não puderam ser obtidos nos dados consolidados
+type GoogleAdsItemError struct {
+	Tipo  string     `json:"tipo"`           // conta
+	ID    string     `json:"id"`             // ID da conta
+	Nome  string     `json:"nome,omitempty"` // Nome da conta
+	Error *ErrorInfo `json:"error"`          // Detalhes do erro
 }
 
 // GoogleAdsResponse representa a resposta com dados do Google Ads
@@ -30,6 +57,28 @@ type GoogleAdsResponse struct {
 	Error   *ErrorInfo    `json:"error,omitempty"` // Informações de erro, se houver
 }
 
+// GoogleAdsSeriesResponse representa a resposta com uma série temporal de dados do Google Ads
+type GoogleAdsSeriesResponse struct {
+	Success bool            `json:"success"`         // Indica se a operação foi bem-sucedida
+	Message string          `json:"message"`         // Mensagem descritiva
+	Data    []GoogleAdsData `json:"data,omitempty"`  // Um ponto por período
+	Error   *ErrorInfo      `json:"error,omitempty"` // Informações de erro, se houver
+}
+
+// GoogleAdsSegment contém as métricas do Google Ads de uma combinação de valores dos breakdowns
+type GoogleAdsSegment struct {
+	Segmento map[string]string `json:"segmento"` // Valores dos breakdowns (ex: {"device": "MOBILE"})
+	GoogleAdsData
+}
+
+// GoogleAdsSegmentsResponse representa a resposta com dados do Google Ads segmentados por breakdowns
+type GoogleAdsSegmentsResponse struct {
+	Success bool               `json:"success"`         // Indica se a operação foi bem-sucedida
+	Message string             `json:"message"`         // Mensagem descritiva
+	Data    []GoogleAdsSegment `json:"data,omitempty"`  // Uma linha por combinação de segmentos
+	Error   *ErrorInfo         `json:"error,omitempty"` // Informações de erro, se houver
+}
+
 // GoogleAdsCampaignListResponse representa a resposta com lista de campanhas
 type GoogleAdsCampaignListResponse struct {
 	Success bool             `json:"success"`           // Indica se a operação foi bem-sucedida
@@ -38,6 +87,86 @@ type GoogleAdsCampaignListResponse struct {
 	Error   *ErrorInfo       `json:"error,omitempty"` // Informações de erro, se houver
 }
 
+// GoogleAdsAdGroupData contém os dados e as métricas de um grupo de anúncios do Google Ads
+type GoogleAdsAdGroupData struct {
+	GoogleAdsData
+	CampanhaID   string `json:"campanha_id"`             // ID da campanha do grupo
+	CampanhaNome string `json:"campanha_nome,omitempty"` // Nome da campanha
+	Status       string `json:"status"`                  // Status do grupo (ENABLED, PAUSED, ...)
+}
+
+// GoogleAdsAdData contém os dados e as métricas de um anúncio responsivo de pesquisa do Google Ads
+type GoogleAdsAdData struct {
+	GoogleAdsData
+	CampanhaID      string   `json:"campanha_id"`                // ID da campanha do anúncio
+	GrupoID         string   `json:"grupo_id"`                   // ID do grupo de anúncios
+	GrupoNome       string   `json:"grupo_nome,omitempty"`       // Nome do grupo de anúncios
+	Status          string   `json:"status"`                     // Status do anúncio (ENABLED, PAUSED, ...)
+	StatusAprovacao string   `json:"status_aprovacao,omitempty"` // Status de aprovação da política (APPROVED, DISAPPROVED, ...)
+	Titulos         []string `json:"titulos"`                    // Títulos do anúncio responsivo
+	Descricoes      []string `json:"descricoes"`                 // Descrições do anúncio responsivo
+	URLsFinais      []string `json:"urls_finais,omitempty"`      // URLs finais do anúncio
+}
+
+// GoogleAdsKeywordData contém os dados, as métricas e o índice de qualidade de uma palavra-chave do Google Ads
+type GoogleAdsKeywordData struct {
+	GoogleAdsData
+	CampanhaID               string `json:"campanha_id"`                          // ID da campanha da palavra-chave
+	GrupoID                  string `json:"grupo_id"`                             // ID do grupo de anúncios
+	GrupoNome                string `json:"grupo_nome,omitempty"`                 // Nome do grupo de anúncios
+	TipoCorrespondencia      string `json:"tipo_correspondencia"`                 // EXACT, PHRASE ou BROAD
+	Status                   string `json:"status"`                               // Status da palavra-chave (ENABLED, PAUSED, ...)
+	IndiceQualidade          *int   `json:"indice_qualidade,omitempty"`           // Índice de qualidade de 1 a 10; ausente quando o Google não o calcula
+	QualidadeAnuncio         string `json:"qualidade_anuncio,omitempty"`          // Relevância do anúncio (ABOVE_AVERAGE, AVERAGE, BELOW_AVERAGE)
+	ExperienciaPaginaDestino string `json:"experiencia_pagina_destino,omitempty"` // Experiência na página de destino
+	CTREsperada              string `json:"ctr_esperada,omitempty"`               // CTR esperada
+}
+
+// GoogleAdsSearchTermData contém as métricas de um termo de pesquisa do Google Ads; o termo vem em Nome
+type GoogleAdsSearchTermData struct {
+	GoogleAdsData
+	StatusTermo              string `json:"status_termo"`                         // ADDED, EXCLUDED, ADDED_EXCLUDED ou NONE
+	TipoCorrespondenciaTermo string `json:"tipo_correspondencia_termo,omitempty"` // Como o termo correspondeu à palavra-chave (EXACT, NEAR_EXACT, PHRASE, ...)
+	PalavraChave             string `json:"palavra_chave,omitempty"`              // Palavra-chave que acionou o anúncio
+	TipoCorrespondencia      string `json:"tipo_correspondencia,omitempty"`       // Tipo de correspondência da palavra-chave
+	CampanhaID               string `json:"campanha_id"`                          // ID da campanha
+	CampanhaNome             string `json:"campanha_nome,omitempty"`              // Nome da campanha
+	GrupoID                  string `json:"grupo_id"`                             // ID do grupo de anúncios
+	GrupoNome                string `json:"grupo_nome,omitempty"`                 // Nome do grupo de anúncios
+}
+
+// GoogleAdsSearchTermListResponse representa a resposta com a lista de termos de pesquisa
+type GoogleAdsSearchTermListResponse struct {
+	Success bool                      `json:"success"`         // Indica se a operação foi bem-sucedida
+	Message string                    `json:"message"`         // Mensagem descritiva
+	Data    []GoogleAdsSearchTermData `json:"data,omitempty"`  // Lista de termos de pesquisa
+	Error   *ErrorInfo                `json:"error,omitempty"` // Informações de erro, se houver
+}
+
+// GoogleAdsAdGroupListResponse representa a resposta com a lista de grupos de anúncios
+type GoogleAdsAdGroupListResponse struct {
+	Success bool                   `json:"success"`         // Indica se a operação foi bem-sucedida
+	Message string                 `json:"message"`         // Mensagem descritiva
+	Data    []GoogleAdsAdGroupData `json:"data,omitempty"`  // Lista de grupos de anúncios
+	Error   *ErrorInfo             `json:"error,omitempty"` // Informações de erro, se houver
+}
+
+// GoogleAdsAdListResponse representa a resposta com a lista de anúncios
+type GoogleAdsAdListResponse struct {
+	Success bool              `json:"success"`         // Indica se a operação foi bem-sucedida
+	Message string            `json:"message"`         // Mensagem descritiva
+	Data    []GoogleAdsAdData `json:"data,omitempty"`  // Lista de anúncios
+	Error   *ErrorInfo        `json:"error,omitempty"` // Informações de erro, se houver
+}
+
+// GoogleAdsKeywordListResponse representa a resposta com a lista de palavras-chave
+type GoogleAdsKeywordListResponse struct {
+	Success bool                   `json:"success"`         // Indica se a operação foi bem-sucedida
+	Message string                 `json:"message"`         // Mensagem descritiva
+	Data    []GoogleAdsKeywordData `json:"data,omitempty"`  // Lista de palavras-chave
+	Error   *ErrorInfo             `json:"error,omitempty"` // Informações de erro, se houver
+}
+
 // GoogleAdsAccountInfo representa as informações básicas de uma conta do Google Ads
 type GoogleAdsAccountInfo struct {
 	ID               string `json:"id"`                      // ID da conta
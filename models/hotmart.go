@@ -46,6 +46,11 @@ type HotmartWebhook struct {
 		} `json:"full_price"`
 		Transaction string `json:"transaction"`
 		Status      string `json:"status"` // APPROVED, DISPUTE, EXPIRED, etc
+		Origin      struct { // Parâmetros de rastreamento do checkout
+			Src  string `json:"src"`
+			Sck  string `json:"sck"`
+			Xcod string `json:"xcod"`
+		} `json:"origin"`
 	} `json:"purchase,omitempty"`
 	Affiliates []struct {
 		AffiliateCode string `json:"affiliate_code"`
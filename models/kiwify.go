@@ -10,6 +10,7 @@ type KiwifyWebhook struct {
 	CreatedAt       string `json:"created_at"`
 	Commissions     struct {
 		Currency           string `json:"currency"`
+		ChargeAmount       int    `json:"charge_amount"` // Valor cobrado em centavos
 		CommissionedStores []struct {
 			CustomName string `json:"custom_name"`
 			Type      string `json:"type"`
@@ -60,6 +61,15 @@ type KiwifyWebhook struct {
 		UTMContent  string `json:"utm_content"`
 		UTMTerm     string `json:"utm_term"`
 	} `json:"tracking_data"`
+	TrackingParameters struct { // Formato atual enviado pela Kiwify
+		Src         string `json:"src"`
+		Sck         string `json:"sck"`
+		UTMSource   string `json:"utm_source"`
+		UTMMedium   string `json:"utm_medium"`
+		UTMCampaign string `json:"utm_campaign"`
+		UTMContent  string `json:"utm_content"`
+		UTMTerm     string `json:"utm_term"`
+	} `json:"TrackingParameters"`
 	Subscription struct {
 		ID     string `json:"id"`
 		Status string `json:"status"`
@@ -77,12 +87,14 @@ type KiwifyWebhook struct {
 type KiwifyAbandonedCart struct {
 	CheckoutLink      string `json:"checkout_link"`
 	Country          string `json:"country"`
+	CreatedAt        string `json:"created_at"`
 	CNPJ             string `json:"cnpj"`
 	Email            string `json:"email"`
 	Name             string `json:"name"`
 	Phone            string `json:"phone"`
 	ProductID        string `json:"product_id"`
 	ProductName      string `json:"product_name"`
+	Status           string `json:"status"`
 	StoreID          string `json:"store_id"`
 	SubscriptionPlan interface{} `json:"subscription_plan"`
 }
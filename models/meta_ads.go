@@ -1,8 +1,11 @@
 package models
 
+import "time"
+
 // MetaAdsRequest representa a solicitação para consulta de dados do Meta Ads
 type MetaAdsRequest struct {
-	Token string `json:"token" binding:"required"`
+	Token       string   `json:"token" binding:"required"`
+	ActionTypes []string `json:"action_types,omitempty"` // Tipos de ação contados como venda (opcional)
 }
 
 // OAuthTokenResponse estrutura para decodificar a resposta JSON do token de acesso OAuth
@@ -17,10 +20,21 @@ type OAuthTokenResponse struct {
 type MetaAdsData struct {
 	ID              string  `json:"id,omitempty"`              // ID da campanha ou conta
 	Nome            string  `json:"nome,omitempty"`            // Nome da campanha ou conta
+	DataInicio      string  `json:"data_inicio,omitempty"`     // Início do período dos dados (AAAA-MM-DD)
+	DataFim         string  `json:"data_fim,omitempty"`        // Fim do período dos dados (AAAA-MM-DD)
 	CTR             float64 `json:"ctr"`                       // Click-Through Rate
 	CAC             float64 `json:"cac"`                       // Custo de Aquisição por Cliente
 	InvestimentoTotal float64 `json:"investimento_total"`       // Investimento Total
 	NumeroVendas    int     `json:"numero_vendas"`             // Número de Vendas
+	ValorVendas     float64 `json:"valor_vendas"`              // Valor total das vendas (action_values)
+	ROAS            float64 `json:"roas"`                      // Retorno sobre o investimento em anúncios
+	CPM             float64 `json:"cpm"`                       // Custo por mil impressões
+	CPC             float64 `json:"cpc"`                       // Custo por clique
+	Impressoes      int     `json:"impressoes"`                // Número de impressões
+	Cliques         int     `json:"cliques"`                   // Número de cliques
+	Alcance         int     `json:"alcance"`                   // Pessoas alcançadas
+	Frequencia      float64 `json:"frequencia"`                // Média de impressões por pessoa
+	Moeda           string  `json:"moeda,omitempty"`           // Moeda da conta em que os valores estão expressos (ex: BRL, USD)
 }
 
 // MetaAdsResponse representa a resposta com dados do Meta Ads
@@ -31,6 +45,170 @@ type MetaAdsResponse struct {
 	Error   *ErrorInfo  `json:"error,omitempty"` // Informações de erro, se houver
 }
 
+// MetaAdsSeriesResponse representa a resposta com uma série temporal de dados do Meta Ads
+type MetaAdsSeriesResponse struct {
+	Success bool          `json:"success"`         // Indica se a operação foi bem-sucedida
+	Message string        `json:"message"`         // Mensagem descritiva
+	Data    []MetaAdsData `json:"data,omitempty"`  // Um ponto por período
+	Error   *ErrorInfo    `json:"error,omitempty"` // Informações de erro, se houver
+}
+
+// MetaAdsSegment representa uma linha de insights do Meta Ads segmentada por breakdowns
+type MetaAdsSegment struct {
+	Segmento map[string]string `json:"segmento"` // Valores dos breakdowns da linha (ex: {"age": "25-34", "gender": "female"})
+	MetaAdsData
+	Acoes []MetaAdsActionSegment `json:"acoes,omitempty"` // Vendas detalhadas pelos action_breakdowns
+}
+
+// MetaAdsActionSegment contém as vendas de uma linha para uma combinação de action_breakdowns
+type MetaAdsActionSegment struct {
+	Segmento    map[string]string `json:"segmento"`     // Valores dos action_breakdowns (ex: {"action_device": "iphone"})
+	Vendas      float64           `json:"vendas"`       // Número de vendas
+	ValorVendas float64           `json:"valor_vendas"` // Valor das vendas
+}
+
+// MetaAdsSegmentsResponse representa a resposta com insights do Meta Ads segmentados
+type MetaAdsSegmentsResponse struct {
+	Success bool             `json:"success"`         // Indica se a operação foi bem-sucedida
+	Message string           `json:"message"`         // Mensagem descritiva
+	Data    []MetaAdsSegment `json:"data,omitempty"`  // Uma linha por segmento (e por período, com time_increment)
+	Error   *ErrorInfo       `json:"error,omitempty"` // Informações de erro, se houver
+}
+
+// MetaAdSetData contém os dados e as métricas de um conjunto de anúncios do Meta Ads
+type MetaAdSetData struct {
+	MetaAdsData
+	CampanhaID      string  `json:"campanha_id"`                // ID da campanha do conjunto
+	Status          string  `json:"status"`                     // Status configurado (ACTIVE, PAUSED, ...)
+	StatusEfetivo   string  `json:"status_efetivo"`             // Status efetivo de veiculação
+	OrcamentoDiario float64 `json:"orcamento_diario"`           // Orçamento diário (zero se o orçamento for da campanha)
+	OrcamentoTotal  float64 `json:"orcamento_total"`            // Orçamento vitalício (zero se não houver)
+	EstrategiaLance string  `json:"estrategia_lance,omitempty"` // Estratégia de lance (bid_strategy)
+}
+
+// MetaAdData contém os dados e as métricas de um anúncio do Meta Ads
+type MetaAdData struct {
+	MetaAdsData
+	CampanhaID    string `json:"campanha_id"`           // ID da campanha do anúncio
+	ConjuntoID    string `json:"conjunto_id"`           // ID do conjunto de anúncios
+	Status        string `json:"status"`                // Status configurado (ACTIVE, PAUSED, ...)
+	StatusEfetivo string `json:"status_efetivo"`        // Status efetivo de veiculação
+	CriativoID    string `json:"criativo_id,omitempty"` // ID do criativo utilizado
+}
+
+// MetaAdSetListResponse representa a resposta com a lista de conjuntos de anúncios
+type MetaAdSetListResponse struct {
+	Success bool            `json:"success"`         // Indica se a operação foi bem-sucedida
+	Message string          `json:"message"`         // Mensagem descritiva
+	Data    []MetaAdSetData `json:"data,omitempty"`  // Lista de conjuntos de anúncios
+	Error   *ErrorInfo      `json:"error,omitempty"` // Informações de erro, se houver
+}
+
+// MetaAdListResponse representa a resposta com a lista de anúncios
+type MetaAdListResponse struct {
+	Success bool         `json:"success"`         // Indica se a operação foi bem-sucedida
+	Message string       `json:"message"`         // Mensagem descritiva
+	Data    []MetaAdData `json:"data,omitempty"`  // Lista de anúncios
+	Error   *ErrorInfo   `json:"error,omitempty"` // Informações de erro, se houver
+}
+
+// MetaAdsAccountInfo representa as informações de uma conta de anúncios do Meta Ads
+type MetaAdsAccountInfo struct {
+	ID           string  `json:"id"`                     // ID da conta (sem o prefixo act_)
+	Nome         string  `json:"nome"`                   // Nome da conta
+	Moeda        string  `json:"moeda"`                  // Código da moeda (ex: BRL, USD)
+	FusoHorario  string  `json:"fuso_horario"`           // Fuso horário da conta (ex: America/Sao_Paulo)
+	Status       string  `json:"status"`                 // Status da conta (ACTIVE, DISABLED, UNSETTLED, ...)
+	StatusCodigo int     `json:"status_codigo"`          // Código numérico do account_status
+	LimiteGastos float64 `json:"limite_gastos"`          // Limite de gastos da conta (zero se não houver)
+	ValorGasto   float64 `json:"valor_gasto"`            // Total gasto desde a criação da conta ou da última redefinição do limite
+	EmpresaID    string  `json:"empresa_id,omitempty"`   // ID do Business Manager dono da conta
+	EmpresaNome  string  `json:"empresa_nome,omitempty"` // Nome do Business Manager
+}
+
+// MetaAdsAccountInfoResponse representa a resposta com as informações de uma conta do Meta Ads
+type MetaAdsAccountInfoResponse struct {
+	Success bool                `json:"success"`         // Indica se a operação foi bem-sucedida
+	Message string              `json:"message"`         // Mensagem descritiva
+	Data    *MetaAdsAccountInfo `json:"data,omitempty"`  // Informações da conta
+	Error   *ErrorInfo          `json:"error,omitempty"` // Informações de erro, se houver
+}
+
+// MetaTokenInfo representa as informações de um token de acesso retornadas pelo debug_token do Meta
+type MetaTokenInfo struct {
+	Valido              bool       `json:"valido"`                           // Indica se o token ainda é válido
+	Tipo                string     `json:"tipo,omitempty"`                   // USER, PAGE, APP ou SYSTEM_USER
+	AppID               string     `json:"app_id,omitempty"`                 // ID do aplicativo que emitiu o token
+	Aplicacao           string     `json:"aplicacao,omitempty"`              // Nome do aplicativo
+	UserID              string     `json:"user_id,omitempty"`                // ID do usuário dono do token
+	Escopos             []string   `json:"escopos"`                          // Permissões concedidas
+	EmitidoEm           *time.Time `json:"emitido_em,omitempty"`             // Data de emissão
+	ExpiraEm            *time.Time `json:"expira_em,omitempty"`              // Data de expiração; ausente se o token não expira
+	AcessoDadosExpiraEm *time.Time `json:"acesso_dados_expira_em,omitempty"` // Data em que o acesso aos dados do usuário expira
+	DiasRestantes       *int       `json:"dias_restantes,omitempty"`         // Dias até a expiração
+	PrecisaRenovar      bool       `json:"precisa_renovar"`                  // Token inválido ou expirando nos próximos dias
+	Erro                string     `json:"erro,omitempty"`                   // Motivo da invalidade, se houver
+}
+
+// MetaTokenInfoResponse representa a resposta da inspeção de um token do Meta Ads
+type MetaTokenInfoResponse struct {
+	Success bool           `json:"success"`         // Indica se a operação foi bem-sucedida
+	Message string         `json:"message"`         // Mensagem descritiva
+	Data    *MetaTokenInfo `json:"data,omitempty"`  // Informações do token
+	Error   *ErrorInfo     `json:"error,omitempty"` // Informações de erro, se houver
+}
+
+// MetaAdsItemError descreve uma conta ou campanha cujos dados não puderam ser obtidos
+type MetaAdsItemError struct {
+	Tipo  string     `json:"tipo"`           // conta ou campanha
+	ID    string     `json:"id"`             // ID da conta ou campanha
+	Nome  string     `json:"nome,omitempty"` // Nome da conta ou campanha
+	Error *ErrorInfo `json:"error"`          // Detalhes do erro
+}
+
+// MetaReportJobRequest representa a solicitação de um relatório assíncrono de insights do Meta Ads
+type MetaReportJobRequest struct {
+	Token            string   `json:"token" binding:"required"`
+	ContaID          string   `json:"conta_id" binding:"required"` // ID da conta de anúncios (sem o prefixo act_)
+	Nivel            string   `json:"nivel,omitempty"`             // account, campaign, adset ou ad (padrão: campaign)
+	ActionTypes      []string `json:"action_types,omitempty"`      // Tipos de ação contados como venda
+	DatePreset       string   `json:"date_preset,omitempty"`       // Período predefinido do Meta
+	Since            string   `json:"since,omitempty"`             // Data inicial (AAAA-MM-DD)
+	Until            string   `json:"until,omitempty"`             // Data final (AAAA-MM-DD)
+	TimeIncrement    string   `json:"time_increment,omitempty"`    // daily, weekly, monthly ou número de dias
+	Breakdowns       []string `json:"breakdowns,omitempty"`        // Segmentações de público/posicionamento
+	ActionBreakdowns []string `json:"action_breakdowns,omitempty"` // Segmentações das ações
+}
+
+// MetaReportJob representa o estado de um relatório assíncrono de insights do Meta Ads
+type MetaReportJob struct {
+	ID          string     `json:"id"`                     // ID do relatório (report_run_id do Meta)
+	ContaID     string     `json:"conta_id"`               // ID da conta de anúncios
+	Nivel       string     `json:"nivel"`                  // Nível das linhas do relatório
+	Status      string     `json:"status"`                 // pendente, executando, concluido ou falhou
+	StatusMeta  string     `json:"status_meta,omitempty"`  // Valor original de async_status
+	Percentual  int        `json:"percentual"`             // async_percent_completion
+	CriadoEm    time.Time  `json:"criado_em"`              // Data de envio do relatório
+	ConcluidoEm *time.Time `json:"concluido_em,omitempty"` // Data em que o relatório foi concluído
+}
+
+// MetaReportJobResponse representa a resposta com o estado de um relatório assíncrono
+type MetaReportJobResponse struct {
+	Success bool           `json:"success"`         // Indica se a operação foi bem-sucedida
+	Message string         `json:"message"`         // Mensagem descritiva
+	Data    *MetaReportJob `json:"data,omitempty"`  // Estado do relatório
+	Error   *ErrorInfo     `json:"error,omitempty"` // Informações de erro, se houver
+}
+
+// MetaReportResultResponse representa a resposta com as linhas de um relatório assíncrono concluído
+type MetaReportResultResponse struct {
+	Success bool             `json:"success"`         // Indica se a operação foi bem-sucedida
+	Message string           `json:"message"`         // Mensagem descritiva
+	Job     *MetaReportJob   `json:"job,omitempty"`   // Estado do relatório
+	Data    []MetaAdsSegment `json:"data,omitempty"`  // Linhas do relatório com métricas e segmentos
+	Error   *ErrorInfo       `json:"error,omitempty"` // Informações de erro, se houver
+}
+
 // ErrorInfo representa informações detalhadas sobre um erro
 type ErrorInfo struct {
 	Code    int    `json:"code,omitempty"`
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// MetaUpdateRequest representa a solicitação de alteração de uma campanha ou conjunto de anúncios do Meta Ads
+type MetaUpdateRequest struct {
+	Token           string   `json:"token" binding:"required"`
+	Status          string   `json:"status,omitempty"`           // ACTIVE ou PAUSED
+	OrcamentoDiario *float64 `json:"orcamento_diario,omitempty"` // Novo orçamento diário, na moeda da conta
+	OrcamentoTotal  *float64 `json:"orcamento_total,omitempty"`  // Novo orçamento total (vitalício), na moeda da conta
+	DataFim         string   `json:"data_fim,omitempty"`         // Nova data de término (AAAA-MM-DD ou RFC3339)
+	DryRun          bool     `json:"dry_run,omitempty"`          // Apenas simula a alteração, sem enviá-la ao Meta
+}
+
+// MetaChange representa a alteração de um campo
+type MetaChange struct {
+	Campo    string `json:"campo"`    // Campo no Meta (status, daily_budget, ...)
+	Anterior string `json:"anterior"` // Valor antes da alteração
+	Novo     string `json:"novo"`     // Valor solicitado
+}
+
+// MetaAuditRecord registra quem alterou o quê em uma campanha ou conjunto de anúncios
+type MetaAuditRecord struct {
+	ID          string       `json:"id"`
+	DataHora    time.Time    `json:"data_hora"`
+	Objeto      string       `json:"objeto"`    // campaign ou adset
+	ObjetoID    string       `json:"objeto_id"` // ID da campanha ou do conjunto
+	ObjetoNome  string       `json:"objeto_nome,omitempty"`
+	UsuarioID   string       `json:"usuario_id,omitempty"`   // Usuário do Meta dono do token
+	UsuarioNome string       `json:"usuario_nome,omitempty"` // Nome do usuário do Meta
+	Alteracoes  []MetaChange `json:"alteracoes"`
+	DryRun      bool         `json:"dry_run"`
+	Sucesso     bool         `json:"sucesso"`
+	Erro        string       `json:"erro,omitempty"`
+}
+
+// MetaUpdateResponse representa a resposta de uma alteração no Meta Ads
+type MetaUpdateResponse struct {
+	Success bool             `json:"success"`         // Indica se a operação foi bem-sucedida
+	Message string           `json:"message"`         // Mensagem descritiva
+	Data    *MetaAuditRecord `json:"data,omitempty"`  // Registro de auditoria da alteração
+	Error   *ErrorInfo       `json:"error,omitempty"` // Informações de erro, se houver
+}
+
+// MetaAuditListResponse representa a resposta com o histórico de alterações
+type MetaAuditListResponse struct {
+	Success bool              `json:"success"`         // Indica se a operação foi bem-sucedida
+	Message string            `json:"message"`         // Mensagem descritiva
+	Data    []MetaAuditRecord `json:"data"`            // Alterações registradas, das mais recentes para as mais antigas
+	Error   *ErrorInfo        `json:"error,omitempty"` // Informações de erro, se houver
+}
@@ -0,0 +1,85 @@
+package models
+
+// MetaWebhookPayload representa as notificações enviadas pelo Meta ao webhook /webhook/meta
+type MetaWebhookPayload struct {
+	Object string             `json:"object"` // Tipo do objeto assinado (page)
+	Entry  []MetaWebhookEntry `json:"entry"`
+}
+
+// MetaWebhookEntry agrupa as alterações de um objeto (página) em uma notificação
+type MetaWebhookEntry struct {
+	ID      string              `json:"id"`   // ID da página
+	Time    int64               `json:"time"` // Momento da notificação (Unix)
+	Changes []MetaWebhookChange `json:"changes"`
+}
+
+// MetaWebhookChange representa uma alteração notificada; para Lead Ads o campo é leadgen
+type MetaWebhookChange struct {
+	Field string           `json:"field"`
+	Value MetaLeadgenValue `json:"value"`
+}
+
+// MetaLeadgenValue contém os identificadores de um novo lead
+type MetaLeadgenValue struct {
+	LeadgenID   string `json:"leadgen_id"`
+	PageID      string `json:"page_id"`
+	FormID      string `json:"form_id"`
+	AdID        string `json:"ad_id,omitempty"`
+	AdgroupID   string `json:"adgroup_id,omitempty"`
+	CreatedTime int64  `json:"created_time"`
+}
+
+// MetaLead representa um lead obtido pela Graph API
+type MetaLead struct {
+	ID           string          `json:"id"`
+	CreatedTime  string          `json:"created_time"`
+	FormID       string          `json:"form_id"`
+	AdID         string          `json:"ad_id"`
+	AdName       string          `json:"ad_name"`
+	AdsetID      string          `json:"adset_id"`
+	CampaignID   string          `json:"campaign_id"`
+	CampaignName string          `json:"campaign_name"`
+	Platform     string          `json:"platform"` // fb ou ig
+	FieldData    []MetaLeadField `json:"field_data"`
+}
+
+// MetaLeadField representa a resposta de um campo do formulário de lead
+type MetaLeadField struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// MetaPage representa uma página do Facebook cujo token é usado para ler os leads
+type MetaPage struct {
+	ID    string `json:"id"`
+	Nome  string `json:"nome"`
+	Token string `json:"-"` // Token da página; nunca é retornado pela API
+}
+
+// MetaPageRequest representa a solicitação de cadastro dos tokens das páginas de um usuário
+type MetaPageRequest struct {
+	Token string `json:"token" binding:"required"` // Token do usuário com as permissões pages_show_list e leads_retrieval
+}
+
+// MetaPageListResponse representa a resposta com as páginas cadastradas
+type MetaPageListResponse struct {
+	Success bool       `json:"success"`         // Indica se a operação foi bem-sucedida
+	Message string     `json:"message"`         // Mensagem descritiva
+	Data    []MetaPage `json:"data,omitempty"`  // Páginas cadastradas
+	Error   *ErrorInfo `json:"error,omitempty"` // Informações de erro, se houver
+}
+
+// MetaLeadListResponse representa a resposta com os leads armazenados
+type MetaLeadListResponse struct {
+	Success bool            `json:"success"`         // Indica se a operação foi bem-sucedida
+	Message string          `json:"message"`         // Mensagem descritiva
+	Data    []CheckoutEvent `json:"data"`            // Leads recebidos
+	Error   *ErrorInfo      `json:"error,omitempty"` // Informações de erro, se houver
+}
+
+// MetaWebhookResponse representa a resposta do webhook do Meta
+type MetaWebhookResponse struct {
+	Status  string      `json:"status"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// Tipos de público personalizado sincronizados a partir dos eventos de checkout
+const (
+	PublicoCompradores        = "compradores"
+	PublicoCarrinhoAbandonado = "carrinho_abandonado"
+)
+
+// MetaAudienceRequest representa a solicitação de criação de um público personalizado no Meta Ads
+type MetaAudienceRequest struct {
+	Token      string `json:"token" binding:"required"`    // Token com a permissão ads_management
+	ContaID    string `json:"conta_id" binding:"required"` // ID da conta de anúncios (sem o prefixo act_)
+	Nome       string `json:"nome" binding:"required"`     // Nome do público no Meta
+	Tipo       string `json:"tipo" binding:"required"`     // compradores ou carrinho_abandonado
+	ProdutoID  string `json:"produto_id,omitempty"`        // Considera apenas eventos deste produto
+	JanelaDias int    `json:"janela_dias,omitempty"`       // Carrinhos abandonados nos últimos N dias (padrão 7)
+}
+
+// MetaAudience representa um público personalizado mantido em sincronia com os eventos de checkout
+type MetaAudience struct {
+	ID                  string     `json:"id"` // ID do público no Meta
+	ContaID             string     `json:"conta_id"`
+	Nome                string     `json:"nome"`
+	Tipo                string     `json:"tipo"`
+	ProdutoID           string     `json:"produto_id,omitempty"`
+	JanelaDias          int        `json:"janela_dias,omitempty"`
+	Membros             int        `json:"membros"` // Clientes enviados ao Meta na última sincronização
+	UltimaSincronizacao *time.Time `json:"ultima_sincronizacao,omitempty"`
+	UltimoErro          string     `json:"ultimo_erro,omitempty"`
+}
+
+// MetaAudienceSyncResult resume uma sincronização incremental de um público
+type MetaAudienceSyncResult struct {
+	PublicoID   string    `json:"publico_id"`
+	Adicionados int       `json:"adicionados"`
+	Removidos   int       `json:"removidos"`
+	Membros     int       `json:"membros"`
+	DataHora    time.Time `json:"data_hora"`
+}
+
+// MetaAudienceResponse representa a resposta com um público personalizado
+type MetaAudienceResponse struct {
+	Success bool          `json:"success"`         // Indica se a operação foi bem-sucedida
+	Message string        `json:"message"`         // Mensagem descritiva
+	Data    *MetaAudience `json:"data,omitempty"`  // Público personalizado
+	Error   *ErrorInfo    `json:"error,omitempty"` // Informações de erro, se houver
+}
+
+// MetaAudienceListResponse representa a resposta com os públicos personalizados cadastrados
+type MetaAudienceListResponse struct {
+	Success bool           `json:"success"`         // Indica se a operação foi bem-sucedida
+	Message string         `json:"message"`         // Mensagem descritiva
+	Data    []MetaAudience `json:"data"`            // Públicos cadastrados
+	Error   *ErrorInfo     `json:"error,omitempty"` // Informações de erro, se houver
+}
+
+// MetaAudienceSyncResponse representa a resposta de uma sincronização de público
+type MetaAudienceSyncResponse struct {
+	Success bool                    `json:"success"`         // Indica se a operação foi bem-sucedida
+	Message string                  `json:"message"`         // Mensagem descritiva
+	Data    *MetaAudienceSyncResult `json:"data,omitempty"`  // Resumo da sincronização
+	Error   *ErrorInfo              `json:"error,omitempty"` // Informações de erro, se houver
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"poc-integracoes-onm/models"
+)
+
+// AttributionModel define como o crédito de uma venda é distribuído entre os pontos de contato do cliente
+type AttributionModel string
+
+const (
+	// AttributionFirstTouch atribui todo o crédito ao primeiro ponto de contato
+	AttributionFirstTouch AttributionModel = "first_touch"
+	// AttributionLastTouch atribui todo o crédito ao último ponto de contato antes da compra
+	AttributionLastTouch AttributionModel = "last_touch"
+	// AttributionLinear divide o crédito igualmente entre todos os pontos de contato
+	AttributionLinear AttributionModel = "linear"
+	// AttributionTimeDecay dá mais crédito aos pontos de contato mais próximos da compra
+	AttributionTimeDecay AttributionModel = "time_decay"
+)
+
+// DefaultTimeDecayHalfLife é a meia-vida padrão usada no modelo time_decay
+const DefaultTimeDecayHalfLife = 7 * 24 * time.Hour
+
+// MinTimeDecayHalfLife é a menor meia-vida aceita no modelo time_decay; meias-vidas menores fazem os pesos
+// de quase todos os pontos de contato se anularem
+const MinTimeDecayHalfLife = time.Hour
+
+// directChannel identifica vendas sem nenhum ponto de contato com UTM
+const directChannel = "(direto)"
+
+// ParseAttributionModel valida o nome do modelo de atribuição informado pelo cliente
+func ParseAttributionModel(name string) (AttributionModel, error) {
+	switch model := AttributionModel(name); model {
+	case AttributionFirstTouch, AttributionLastTouch, AttributionLinear, AttributionTimeDecay:
+		return model, nil
+	case "":
+		return AttributionLastTouch, nil
+	default:
+		return "", fmt.Errorf("modelo de atribuição inválido: %s (use first_touch, last_touch, linear ou time_decay)", name)
+	}
+}
+
+// AttributionService calcula a atribuição de vendas às campanhas a partir do histórico de eventos dos clientes
+type AttributionService struct {
+	Store    *EventStore
+	HalfLife time.Duration
+}
+
+// NewAttributionService cria uma nova instância do serviço de atribuição
+func NewAttributionService(store *EventStore) *AttributionService {
+	return &AttributionService{
+		Store:    store,
+		HalfLife: DefaultTimeDecayHalfLife,
+	}
+}
+
+// Attribute distribui as vendas entre as campanhas de acordo com o modelo informado.
+// Os pontos de contato de cada venda são os eventos com UTM do mesmo cliente (e-mail)
+// ocorridos desde a venda anterior até a própria venda. Vendas reembolsadas são ignoradas.
+func (s *AttributionService) Attribute(model AttributionModel) ([]models.AttributionResult, error) {
+	if s.Store == nil {
+		return nil, errors.New("armazenamento de eventos não configurado")
+	}
+
+	halfLife := s.HalfLife
+	if halfLife <= 0 {
+		halfLife = DefaultTimeDecayHalfLife
+	}
+
+	results := make(map[string]*models.AttributionResult)
+	credit := func(touch models.CheckoutEvent, weight, valor float64) {
+		key := touch.UTMSource + "|" + touch.UTMMedium + "|" + touch.UTMCampaign
+		result, ok := results[key]
+		if !ok {
+			result = &models.AttributionResult{
+				UTMSource:   touch.UTMSource,
+				UTMMedium:   touch.UTMMedium,
+				UTMCampaign: touch.UTMCampaign,
+			}
+			results[key] = result
+		}
+		result.Conversoes += weight
+		result.Receita += weight * valor
+		result.Toques++
+	}
+
+	for _, history := range s.Store.GroupByCustomer() {
+		refunded := make(map[string]bool)
+		for _, event := range history {
+			if event.Tipo == models.EventoReembolso {
+				refunded[event.Plataforma+"|"+event.ID] = true
+			}
+		}
+
+		var touches []models.CheckoutEvent
+		for _, event := range history {
+			if event.HasUTM() {
+				touches = append(touches, event)
+			}
+
+			if event.Tipo != models.EventoCompra {
+				continue
+			}
+
+			if !refunded[event.Plataforma+"|"+event.ID] {
+				if len(touches) == 0 {
+					credit(models.CheckoutEvent{UTMSource: directChannel}, 1, event.Valor)
+				} else {
+					weights := attributionWeights(model, touches, event.DataEvento, halfLife)
+					for i, touch := range touches {
+						if weights[i] > 0 {
+							credit(touch, weights[i], event.Valor)
+						}
+					}
+				}
+			}
+
+			// A próxima venda do cliente começa uma nova jornada
+			touches = nil
+		}
+	}
+
+	attributed := make([]models.AttributionResult, 0, len(results))
+	for _, result := range results {
+		result.Conversoes = roundFloat(result.Conversoes, 4)
+		result.Receita = roundFloat(result.Receita, 2)
+		attributed = append(attributed, *result)
+	}
+
+	sort.Slice(attributed, func(i, j int) bool {
+		if attributed[i].Receita != attributed[j].Receita {
+			return attributed[i].Receita > attributed[j].Receita
+		}
+		return attributed[i].UTMCampaign < attributed[j].UTMCampaign
+	})
+
+	return attributed, nil
+}
+
+// attributionWeights calcula o peso de cada ponto de contato; a soma dos pesos é sempre 1
+func attributionWeights(model AttributionModel, touches []models.CheckoutEvent, conversion time.Time, halfLife time.Duration) []float64 {
+	weights := make([]float64, len(touches))
+
+	switch model {
+	case AttributionFirstTouch:
+		weights[0] = 1
+	case AttributionLinear:
+		for i := range weights {
+			weights[i] = 1 / float64(len(touches))
+		}
+	case AttributionTimeDecay:
+		var total float64
+		for i, touch := range touches {
+			age := conversion.Sub(touch.DataEvento)
+			if age < 0 {
+				age = 0
+			}
+			weights[i] = math.Pow(2, -float64(age)/float64(halfLife))
+			total += weights[i]
+		}
+		if total == 0 {
+			// Todos os pesos se anularam (pontos de contato muito antigos para a meia-vida): usar o último ponto de contato
+			weights[len(weights)-1] = 1
+			break
+		}
+		for i := range weights {
+			weights[i] /= total
+		}
+	default:
+		weights[len(weights)-1] = 1
+	}
+
+	return weights
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"poc-integracoes-onm/models"
+)
+
+// Formatos de data utilizados pelas plataformas de checkout
+var checkoutDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05-0700", // Graph API do Meta
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// parseEventTime converte a data enviada pela plataforma, usando o horário atual se não for possível interpretá-la
+func parseEventTime(value string) time.Time {
+	for _, layout := range checkoutDateLayouts {
+		if t, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+			return t
+		}
+	}
+	return time.Now()
+}
+
+// parseMillis converte um timestamp em milissegundos, usando o horário atual se não for informado
+func parseMillis(value int64) time.Time {
+	if value <= 0 {
+		return time.Now()
+	}
+	return time.UnixMilli(value)
+}
+
+// parseBRLPrice converte valores monetários como "R$ 1.169,80" ou "169.80" para float
+func parseBRLPrice(value string) float64 {
+	value = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(value), "R$"))
+	if strings.Contains(value, ",") {
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.ReplaceAll(value, ",", ".")
+	}
+	price, _ := strconv.ParseFloat(value, 64)
+	return price
+}
+
+// CheckoutEventFromKiwify converte um webhook de pedido da Kiwify em um evento de checkout
+func CheckoutEventFromKiwify(webhook models.KiwifyWebhook) models.CheckoutEvent {
+	tipo := models.EventoOutro
+	switch strings.ToLower(webhook.OrderStatus) {
+	case "paid", "approved":
+		tipo = models.EventoCompra
+	case "refunded", "chargedback":
+		tipo = models.EventoReembolso
+	case "refused":
+		tipo = models.EventoRecusado
+	}
+
+	valor := float64(webhook.Commissions.ChargeAmount) / 100
+	if valor == 0 {
+		valor = parseBRLPrice(webhook.Payment.Value)
+	}
+	if valor == 0 {
+		valor = parseBRLPrice(webhook.Product.Price)
+	}
+
+	moeda := webhook.Commissions.Currency
+	if moeda == "" {
+		moeda = webhook.Payment.Currency
+	}
+
+	event := models.CheckoutEvent{
+		ID:          webhook.OrderID,
+		Plataforma:  "kiwify",
+		Tipo:        tipo,
+		Email:       webhook.Customer.Email,
+		Nome:        webhook.Customer.Name,
+		Telefone:    webhook.Customer.PhoneNumber,
+		ProdutoID:   webhook.Product.ID,
+		ProdutoNome: webhook.Product.Name,
+		Valor:       valor,
+		Moeda:       moeda,
+		UTMSource:   webhook.TrackingData.UTMSource,
+		UTMMedium:   webhook.TrackingData.UTMMedium,
+		UTMCampaign: webhook.TrackingData.UTMCampaign,
+		UTMContent:  webhook.TrackingData.UTMContent,
+		UTMTerm:     webhook.TrackingData.UTMTerm,
+		DataEvento:  parseEventTime(webhook.CreatedAt),
+	}
+
+	// O formato atual da Kiwify envia as UTMs em TrackingParameters
+	if tracking := webhook.TrackingParameters; tracking.UTMSource != "" || tracking.UTMCampaign != "" {
+		event.UTMSource = tracking.UTMSource
+		event.UTMMedium = tracking.UTMMedium
+		event.UTMCampaign = tracking.UTMCampaign
+		event.UTMContent = tracking.UTMContent
+		event.UTMTerm = tracking.UTMTerm
+	}
+
+	return event
+}
+
+// CheckoutEventFromKiwifyAbandonedCart converte um webhook de carrinho abandonado da Kiwify em um evento de checkout
+func CheckoutEventFromKiwifyAbandonedCart(cart models.KiwifyAbandonedCart) models.CheckoutEvent {
+	return models.CheckoutEvent{
+		ID:          cart.CheckoutLink,
+		Plataforma:  "kiwify",
+		Tipo:        models.EventoCarrinhoAbandonado,
+		Email:       cart.Email,
+		Nome:        cart.Name,
+		Telefone:    cart.Phone,
+		ProdutoID:   cart.ProductID,
+		ProdutoNome: cart.ProductName,
+		DataEvento:  parseEventTime(cart.CreatedAt),
+	}
+}
+
+// CheckoutEventFromHotmart converte um webhook da Hotmart em um evento de checkout
+func CheckoutEventFromHotmart(webhook models.HotmartWebhook) models.CheckoutEvent {
+	event := models.CheckoutEvent{
+		ID:          webhook.ID,
+		Plataforma:  "hotmart",
+		Tipo:        models.EventoCarrinhoAbandonado,
+		Email:       webhook.Buyer.Email,
+		Nome:        webhook.Buyer.Name,
+		Telefone:    webhook.Buyer.CheckoutPhone,
+		ProdutoID:   strconv.Itoa(webhook.Product.ID),
+		ProdutoNome: webhook.Product.Name,
+		DataEvento:  parseMillis(webhook.CreationDate),
+	}
+
+	if event.Telefone == "" {
+		event.Telefone = webhook.Buyer.Phone
+	}
+
+	if webhook.Purchase == nil {
+		return event
+	}
+
+	event.ID = webhook.Purchase.Transaction
+	event.Valor = webhook.Purchase.Price.Value
+	event.Moeda = webhook.Purchase.Price.CurrencyValue
+	event.DataEvento = parseMillis(webhook.Purchase.OrderDate)
+	event.UTMSource = webhook.Purchase.Origin.Src
+	event.UTMCampaign = webhook.Purchase.Origin.Sck
+
+	switch strings.ToUpper(webhook.Purchase.Status) {
+	case "APPROVED", "COMPLETE":
+		event.Tipo = models.EventoCompra
+	case "REFUNDED", "CHARGEBACK", "DISPUTE":
+		event.Tipo = models.EventoReembolso
+	case "CANCELED", "EXPIRED":
+		event.Tipo = models.EventoRecusado
+	default:
+		event.Tipo = models.EventoOutro
+	}
+
+	return event
+}
+
+// CheckoutEventFromKirvano converte um webhook da Kirvano em um evento de checkout
+func CheckoutEventFromKirvano(webhook models.KirvanoWebhookBody) models.CheckoutEvent {
+	tipo := models.EventoOutro
+	switch strings.ToUpper(webhook.Event) {
+	case "SALE_APPROVED":
+		tipo = models.EventoCompra
+	case "SALE_REFUNDED", "SALE_CHARGEBACK":
+		tipo = models.EventoReembolso
+	case "SALE_REFUSED":
+		tipo = models.EventoRecusado
+	case "ABANDONED_CART":
+		tipo = models.EventoCarrinhoAbandonado
+	}
+
+	id := webhook.SaleID
+	if id == "" {
+		id = webhook.CheckoutID
+	}
+
+	event := models.CheckoutEvent{
+		ID:          id,
+		Plataforma:  "kirvano",
+		Tipo:        tipo,
+		Email:       webhook.Customer.Email,
+		Nome:        webhook.Customer.Name,
+		Telefone:    webhook.Customer.PhoneNumber,
+		Valor:       parseBRLPrice(webhook.TotalPrice),
+		Moeda:       "BRL",
+		UTMSource:   webhook.UTM.UTMSource,
+		UTMMedium:   webhook.UTM.UTMMedium,
+		UTMCampaign: webhook.UTM.UTMCampaign,
+		UTMContent:  webhook.UTM.UTMContent,
+		UTMTerm:     webhook.UTM.UTMTerm,
+		DataEvento:  parseEventTime(webhook.CreatedAt),
+	}
+
+	// O produto principal é o primeiro que não é order bump
+	for _, product := range webhook.Products {
+		if !product.IsOrderBump {
+			event.ProdutoID = product.ID
+			event.ProdutoNome = product.Name
+			break
+		}
+	}
+
+	return event
+}
+
+// CheckoutEventFromMetaLead converte um lead dos formulários de Lead Ads do Meta em um evento.
+// A campanha e o anúncio de origem são registrados como UTMs para que o lead conte como ponto de contato na atribuição.
+func CheckoutEventFromMetaLead(lead models.MetaLead) models.CheckoutEvent {
+	campos := make(map[string]string)
+	for _, field := range lead.FieldData {
+		campos[field.Name] = strings.Join(field.Values, ", ")
+	}
+
+	nome := campos["full_name"]
+	if nome == "" {
+		nome = strings.TrimSpace(campos["first_name"] + " " + campos["last_name"])
+	}
+
+	source := "facebook"
+	if lead.Platform == "ig" {
+		source = "instagram"
+	}
+
+	campaign := lead.CampaignName
+	if campaign == "" {
+		campaign = lead.CampaignID
+	}
+
+	return models.CheckoutEvent{
+		ID:          lead.ID,
+		Plataforma:  "meta",
+		Tipo:        models.EventoLead,
+		Email:       campos["email"],
+		Nome:        nome,
+		Telefone:    campos["phone_number"],
+		UTMSource:   source,
+		UTMMedium:   "lead_ads",
+		UTMCampaign: campaign,
+		UTMContent:  lead.AdName,
+		DataEvento:  parseEventTime(lead.CreatedTime),
+		Campos:      campos,
+	}
+}
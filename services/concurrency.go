@@ -0,0 +1,28 @@
+package services
+
+import "sync"
+
+// forEachLimit executa fn para cada índice de 0 a n-1 com no máximo workers execuções simultâneas
+func forEachLimit(n int, workers int, fn func(i int)) {
+	if workers > n {
+		workers = n
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"poc-integracoes-onm/models"
+)
+
+// EventStore armazena em memória os eventos de checkout recebidos pelos webhooks
+type EventStore struct {
+	mu     sync.RWMutex
+	events []models.CheckoutEvent
+	index  map[string]int
+}
+
+// NewEventStore cria uma nova instância do armazenamento de eventos
+func NewEventStore() *EventStore {
+	return &EventStore{
+		index: make(map[string]int),
+	}
+}
+
+// eventKey identifica um evento de forma única para evitar duplicidade em reenvios de webhook
+func eventKey(event models.CheckoutEvent) string {
+	return event.Plataforma + "|" + event.Tipo + "|" + event.ID + "|" + event.Email
+}
+
+// Add adiciona um evento ao armazenamento, substituindo um evento idêntico já recebido
+func (s *EventStore) Add(event models.CheckoutEvent) {
+	event.Email = NormalizeEmail(event.Email)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := eventKey(event)
+	if pos, ok := s.index[key]; ok {
+		s.events[pos] = event
+		return
+	}
+
+	s.index[key] = len(s.events)
+	s.events = append(s.events, event)
+}
+
+// List retorna todos os eventos armazenados, ordenados pela data do evento
+func (s *EventStore) List() []models.CheckoutEvent {
+	s.mu.RLock()
+	events := make([]models.CheckoutEvent, len(s.events))
+	copy(events, s.events)
+	s.mu.RUnlock()
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].DataEvento.Before(events[j].DataEvento)
+	})
+	return events
+}
+
+// ListByType retorna os eventos do tipo informado, ordenados pela data do evento
+func (s *EventStore) ListByType(tipo string) []models.CheckoutEvent {
+	events := []models.CheckoutEvent{}
+	for _, event := range s.List() {
+		if event.Tipo == tipo {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// GroupByCustomer retorna o histórico de eventos de cada cliente, agrupado por e-mail e ordenado pela data
+func (s *EventStore) GroupByCustomer() map[string][]models.CheckoutEvent {
+	customers := make(map[string][]models.CheckoutEvent)
+	for _, event := range s.List() {
+		if event.Email == "" {
+			continue
+		}
+		customers[event.Email] = append(customers[event.Email], event)
+	}
+	return customers
+}
+
+// NormalizeEmail normaliza o e-mail para uso como identificador do cliente
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidGAQLQuery indica que a consulta GAQL montada é inválida (campo, operador, valor ou período)
+var ErrInvalidGAQLQuery = errors.New("consulta GAQL inválida")
+
+// gaqlResource descreve um recurso consultável: os recursos cujos campos podem ser selecionados
+// a partir dele (o próprio recurso e os recursos atribuídos) e se aceita métricas
+type gaqlResource struct {
+	attributes []string
+	metrics    bool
+}
+
+// gaqlResources são os recursos usados nas consultas do serviço
+var gaqlResources = map[string]gaqlResource{
+	"customer":         {attributes: []string{"customer"}, metrics: true},
+	"customer_client":  {attributes: []string{"customer_client", "customer"}},
+	"campaign":         {attributes: []string{"campaign", "customer"}, metrics: true},
+	"ad_group":         {attributes: []string{"ad_group", "campaign", "customer"}, metrics: true},
+	"ad_group_ad":      {attributes: []string{"ad_group_ad", "ad_group", "campaign", "customer"}, metrics: true},
+	"keyword_view":     {attributes: []string{"keyword_view", "ad_group_criterion", "ad_group", "campaign", "customer"}, metrics: true},
+	"search_term_view": {attributes: []string{"search_term_view", "ad_group", "campaign", "customer"}, metrics: true},
+	"geographic_view":  {attributes: []string{"geographic_view", "ad_group", "campaign", "customer"}, metrics: true},
+}
+
+// gaqlFields são os campos conhecidos de cada recurso, de métricas e de segmentos
+var gaqlFields = map[string][]string{
+	"customer":        {"id", "descriptive_name", "currency_code", "time_zone", "manager", "status"},
+	"customer_client": {"id", "descriptive_name", "currency_code", "manager", "level", "status"},
+	"campaign":        {"id", "name", "status", "advertising_channel_type"},
+	"ad_group":        {"id", "name", "status", "type"},
+	"ad_group_ad": {
+		"status", "ad.id", "ad.name", "ad.type", "ad.final_urls", "policy_summary.approval_status",
+		"ad.responsive_search_ad.headlines", "ad.responsive_search_ad.descriptions",
+	},
+	"ad_group_criterion": {
+		"criterion_id", "status", "negative", "keyword.text", "keyword.match_type",
+		"quality_info.quality_score", "quality_info.creative_quality_score",
+		"quality_info.post_click_quality_score", "quality_info.search_predicted_ctr",
+	},
+	"keyword_view":     {"resource_name"},
+	"search_term_view": {"search_term", "status"},
+	"geographic_view":  {"country_criterion_id", "location_type"},
+	"metrics": {
+		"clicks", "impressions", "cost_micros", "conversions", "conversions_value", "all_conversions", "all_conversions_value",
+		"search_impression_share", "search_budget_lost_impression_share",
+	},
+	"segments": {
+		"date", "week", "month", "search_term_match_type", "keyword.info.text", "keyword.info.match_type",
+		"device", "ad_network_type", "hour", "geo_target_region", "conversion_action",
+	},
+}
+
+// gaqlOperators são os operadores aceitos nas condições do WHERE
+var gaqlOperators = map[string]bool{
+	"=": true, "!=": true, ">": true, ">=": true, "<": true, "<=": true,
+	"IN": true, "NOT IN": true, "LIKE": true, "NOT LIKE": true,
+	"CONTAINS ANY": true, "CONTAINS ALL": true, "CONTAINS NONE": true,
+	"REGEXP_MATCH": true, "NOT REGEXP_MATCH": true,
+}
+
+// gaqlDateLiterals são os períodos predefinidos aceitos pelo operador DURING
+var gaqlDateLiterals = map[string]bool{
+	"TODAY": true, "YESTERDAY": true, "LAST_7_DAYS": true, "LAST_14_DAYS": true, "LAST_30_DAYS": true,
+	"LAST_BUSINESS_WEEK": true, "THIS_MONTH": true, "LAST_MONTH": true,
+	"THIS_WEEK_SUN_TODAY": true, "THIS_WEEK_MON_TODAY": true, "LAST_WEEK_SUN_SAT": true, "LAST_WEEK_MON_SUN": true,
+}
+
+// gaqlDateLayout é o formato das datas em GAQL
+const gaqlDateLayout = "2006-01-02"
+
+// gaqlQuery monta uma consulta GAQL validando os campos do recurso e escapando os valores literais.
+// Os métodos podem ser encadeados; o primeiro erro encontrado é retornado por Build.
+type gaqlQuery struct {
+	resource   string
+	fields     []string
+	conditions []string
+	orderBy    []string
+	limit      int
+	err        error
+}
+
+// newGAQLQuery inicia uma consulta sobre o recurso informado (FROM)
+func newGAQLQuery(resource string) *gaqlQuery {
+	q := &gaqlQuery{resource: resource}
+	if _, ok := gaqlResources[resource]; !ok {
+		q.fail("recurso desconhecido: %s", resource)
+	}
+	return q
+}
+
+// Select adiciona campos à cláusula SELECT
+func (q *gaqlQuery) Select(fields ...string) *gaqlQuery {
+	for _, field := range fields {
+		if q.checkField(field) {
+			q.fields = append(q.fields, field)
+		}
+	}
+	return q
+}
+
+// Where adiciona uma condição comparando o campo com um valor literal. Strings são colocadas entre aspas
+// com escape; slices são usados com IN e NOT IN.
+func (q *gaqlQuery) Where(field, operator string, value interface{}) *gaqlQuery {
+	if !q.checkField(field) {
+		return q
+	}
+	if !gaqlOperators[operator] {
+		q.fail("operador inválido: %s", operator)
+		return q
+	}
+
+	literal, err := gaqlLiteral(value)
+	if err != nil {
+		q.fail("valor inválido para %s: %v", field, err)
+		return q
+	}
+	if list := strings.HasPrefix(literal, "("); list != (operator == "IN" || operator == "NOT IN") {
+		q.fail("o operador %s não aceita o valor informado para %s", operator, field)
+		return q
+	}
+
+	q.conditions = append(q.conditions, field+" "+operator+" "+literal)
+	return q
+}
+
+// WhereID filtra o campo pelo ID numérico informado como texto (ex: o ID de uma campanha recebido na URL)
+func (q *gaqlQuery) WhereID(field, id string) *gaqlQuery {
+	value, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64)
+	if err != nil || value <= 0 {
+		q.fail("ID inválido para %s: %s", field, id)
+		return q
+	}
+	return q.Where(field, "=", value)
+}
+
+// During filtra segments.date por um período predefinido (ex: LAST_30_DAYS)
+func (q *gaqlQuery) During(dateLiteral string) *gaqlQuery {
+	if !gaqlDateLiterals[dateLiteral] {
+		q.fail("período inválido: %s", dateLiteral)
+		return q
+	}
+	if q.checkField("segments.date") {
+		q.conditions = append(q.conditions, "segments.date DURING "+dateLiteral)
+	}
+	return q
+}
+
+// Between filtra segments.date entre duas datas, inclusive
+func (q *gaqlQuery) Between(start, end time.Time) *gaqlQuery {
+	if end.Before(start) {
+		q.fail("a data final %s é anterior à data inicial %s", end.Format(gaqlDateLayout), start.Format(gaqlDateLayout))
+		return q
+	}
+	if q.checkField("segments.date") {
+		q.conditions = append(q.conditions, fmt.Sprintf("segments.date BETWEEN '%s' AND '%s'", start.Format(gaqlDateLayout), end.Format(gaqlDateLayout)))
+	}
+	return q
+}
+
+// OrderBy adiciona um campo à ordenação, em ordem decrescente se desc for verdadeiro
+func (q *gaqlQuery) OrderBy(field string, desc bool) *gaqlQuery {
+	if q.checkField(field) {
+		direction := " ASC"
+		if desc {
+			direction = " DESC"
+		}
+		q.orderBy = append(q.orderBy, field+direction)
+	}
+	return q
+}
+
+// Limit limita o número de linhas retornadas
+func (q *gaqlQuery) Limit(limit int) *gaqlQuery {
+	if limit <= 0 {
+		q.fail("limite inválido: %d", limit)
+		return q
+	}
+	q.limit = limit
+	return q
+}
+
+// Build retorna a consulta GAQL montada ou o primeiro erro de validação
+func (q *gaqlQuery) Build() (string, error) {
+	if q.err != nil {
+		return "", q.err
+	}
+	if len(q.fields) == 0 {
+		return "", fmt.Errorf("%w: nenhum campo selecionado", ErrInvalidGAQLQuery)
+	}
+
+	var query strings.Builder
+	query.WriteString("SELECT " + strings.Join(q.fields, ", ") + " FROM " + q.resource)
+	if len(q.conditions) > 0 {
+		query.WriteString(" WHERE " + strings.Join(q.conditions, " AND "))
+	}
+	if len(q.orderBy) > 0 {
+		query.WriteString(" ORDER BY " + strings.Join(q.orderBy, ", "))
+	}
+	if q.limit > 0 {
+		query.WriteString(" LIMIT " + strconv.Itoa(q.limit))
+	}
+	return query.String(), nil
+}
+
+// checkField verifica se o campo existe e pode ser usado a partir do recurso da consulta
+func (q *gaqlQuery) checkField(field string) bool {
+	if q.err != nil {
+		return false
+	}
+
+	prefix, name, found := strings.Cut(field, ".")
+	resource := gaqlResources[q.resource]
+	allowed := prefix == "segments" || (prefix == "metrics" && resource.metrics)
+	for _, attribute := range resource.attributes {
+		allowed = allowed || prefix == attribute
+	}
+
+	if !found || !allowed || !containsString(gaqlFields[prefix], name) {
+		q.fail("campo %s não disponível no recurso %s", field, q.resource)
+		return false
+	}
+	return true
+}
+
+// fail registra o primeiro erro de validação da consulta
+func (q *gaqlQuery) fail(format string, args ...interface{}) {
+	if q.err == nil {
+		q.err = fmt.Errorf("%w: %s", ErrInvalidGAQLQuery, fmt.Sprintf(format, args...))
+	}
+}
+
+// gaqlLiteral converte um valor Go em literal GAQL, escapando barras invertidas e aspas das strings
+func gaqlLiteral(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v) + "'", nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case googleAdsInt64:
+		return strconv.FormatInt(int64(v), 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strings.ToUpper(strconv.FormatBool(v)), nil
+	case time.Time:
+		return "'" + v.Format(gaqlDateLayout) + "'", nil
+	case []string:
+		return gaqlList(len(v), func(i int) interface{} { return v[i] })
+	case []int64:
+		return gaqlList(len(v), func(i int) interface{} { return v[i] })
+	default:
+		return "", fmt.Errorf("tipo não suportado: %T", value)
+	}
+}
+
+// gaqlList monta uma lista de literais entre parênteses, usada com IN e NOT IN
+func gaqlList(n int, item func(i int) interface{}) (string, error) {
+	if n == 0 {
+		return "", errors.New("lista vazia")
+	}
+	literals := make([]string, n)
+	for i := range literals {
+		literal, err := gaqlLiteral(item(i))
+		if err != nil {
+			return "", err
+		}
+		literals[i] = literal
+	}
+	return "(" + strings.Join(literals, ", ") + ")", nil
+}
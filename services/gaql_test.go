@@ -0,0 +1,170 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGAQLLiteral(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    string
+		wantErr bool
+	}{
+		{name: "string simples", value: "campanha", want: "'campanha'"},
+		{name: "aspas simples", value: "o'brien", want: `'o\'brien'`},
+		{name: "barra invertida", value: `a\b`, want: `'a\\b'`},
+		{name: "barra invertida antes de aspas", value: `x\' OR 1=1 --`, want: `'x\\\' OR 1=1 --'`},
+		{name: "int64", value: int64(123), want: "123"},
+		{name: "bool", value: true, want: "TRUE"},
+		{name: "data", value: time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC), want: "'2025-01-31'"},
+		{name: "lista de strings", value: []string{"a", "b'c"}, want: `('a', 'b\'c')`},
+		{name: "lista de int64", value: []int64{1, 2}, want: "(1, 2)"},
+		{name: "lista vazia", value: []string{}, wantErr: true},
+		{name: "tipo não suportado", value: struct{}{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := gaqlLiteral(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("gaqlLiteral(%#v) erro = %v, esperado erro = %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("gaqlLiteral(%#v) = %s, esperado %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGAQLQueryBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   func() *gaqlQuery
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "consulta válida",
+			query: func() *gaqlQuery {
+				return newGAQLQuery("campaign").
+					Select("campaign.id", "metrics.clicks").
+					WhereID("campaign.id", "123").
+					OrderBy("metrics.clicks", true).
+					Limit(10)
+			},
+			want: "SELECT campaign.id, metrics.clicks FROM campaign WHERE campaign.id = 123 ORDER BY metrics.clicks DESC LIMIT 10",
+		},
+		{
+			name: "valor com aspas no WHERE",
+			query: func() *gaqlQuery {
+				return newGAQLQuery("campaign").Select("campaign.id").Where("campaign.name", "=", "x' OR campaign.id > 0 --")
+			},
+			want: `SELECT campaign.id FROM campaign WHERE campaign.name = 'x\' OR campaign.id > 0 --'`,
+		},
+		{
+			name: "IN com lista",
+			query: func() *gaqlQuery {
+				return newGAQLQuery("campaign").Select("campaign.id").Where("campaign.status", "IN", []string{"ENABLED", "PAUSED"})
+			},
+			want: "SELECT campaign.id FROM campaign WHERE campaign.status IN ('ENABLED', 'PAUSED')",
+		},
+		{
+			name: "recurso desconhecido",
+			query: func() *gaqlQuery {
+				return newGAQLQuery("campaign; DROP").Select("campaign.id")
+			},
+			wantErr: true,
+		},
+		{
+			name: "campo desconhecido",
+			query: func() *gaqlQuery {
+				return newGAQLQuery("campaign").Select("campaign.id, campaign.name")
+			},
+			wantErr: true,
+		},
+		{
+			name: "campo de recurso não atribuído",
+			query: func() *gaqlQuery {
+				return newGAQLQuery("campaign").Select("ad_group.id")
+			},
+			wantErr: true,
+		},
+		{
+			name: "métricas em recurso sem métricas",
+			query: func() *gaqlQuery {
+				return newGAQLQuery("customer_client").Select("customer_client.id", "metrics.clicks")
+			},
+			wantErr: true,
+		},
+		{
+			name: "operador inválido",
+			query: func() *gaqlQuery {
+				return newGAQLQuery("campaign").Select("campaign.id").Where("campaign.id", "= 1 OR", int64(1))
+			},
+			wantErr: true,
+		},
+		{
+			name: "IN com lista vazia",
+			query: func() *gaqlQuery {
+				return newGAQLQuery("campaign").Select("campaign.id").Where("campaign.status", "IN", []string{})
+			},
+			wantErr: true,
+		},
+		{
+			name: "IN com valor escalar",
+			query: func() *gaqlQuery {
+				return newGAQLQuery("campaign").Select("campaign.id").Where("campaign.status", "IN", "ENABLED")
+			},
+			wantErr: true,
+		},
+		{
+			name: "igualdade com lista",
+			query: func() *gaqlQuery {
+				return newGAQLQuery("campaign").Select("campaign.id").Where("campaign.status", "=", []string{"ENABLED"})
+			},
+			wantErr: true,
+		},
+		{
+			name: "ID não numérico",
+			query: func() *gaqlQuery {
+				return newGAQLQuery("campaign").Select("campaign.id").WhereID("campaign.id", "1 OR 1=1")
+			},
+			wantErr: true,
+		},
+		{
+			name: "período predefinido inválido",
+			query: func() *gaqlQuery {
+				return newGAQLQuery("campaign").Select("campaign.id").During("LAST_30_DAYS OR 1=1")
+			},
+			wantErr: true,
+		},
+		{
+			name: "nenhum campo selecionado",
+			query: func() *gaqlQuery {
+				return newGAQLQuery("campaign")
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.query().Build()
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidGAQLQuery) {
+					t.Fatalf("Build() erro = %v, esperado ErrInvalidGAQLQuery", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Build() erro inesperado: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Build() = %s\nesperado  %s", got, tt.want)
+			}
+		})
+	}
+}
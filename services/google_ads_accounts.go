@@ -0,0 +1,246 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"poc-integracoes-onm/models"
+)
+
+// DefaultGoogleAdsConcurrency é o número padrão de contas consultadas simultaneamente nos dados consolidados
+const DefaultGoogleAdsConcurrency = 5
+
+// googleAdsConcurrency lê o número de consultas simultâneas da variável de ambiente GOOGLE_ADS_CONCURRENCY
+func googleAdsConcurrency() int {
+	if value, err := strconv.Atoi(os.Getenv("GOOGLE_ADS_CONCURRENCY")); err == nil && value > 0 {
+		return value
+	}
+	return DefaultGoogleAdsConcurrency
+}
+
+// googleAdsAccount representa uma conta cliente (não gerenciadora) encontrada na hierarquia de contas
+type googleAdsAccount struct {
+	ID              string
+	Nome            string
+	Moeda           string
+	LoginCustomerID string // Conta raiz da hierarquia, enviada como login-customer-id para acessar a conta
+}
+
+// listAccessibleCustomers retorna os IDs das contas às quais o usuário do token tem acesso direto
+func (s *GoogleAdsService) listAccessibleCustomers(accessToken string) ([]string, error) {
+	req, err := s.newRequest("GET", "/customers:listAccessibleCustomers", accessToken, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar contas acessíveis: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		ResourceNames []string `json:"resourceNames"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar resposta da listagem de contas: %w", err)
+	}
+
+	customerIDs := make([]string, 0, len(response.ResourceNames))
+	for _, resourceName := range response.ResourceNames {
+		customerIDs = append(customerIDs, strings.TrimPrefix(resourceName, "customers/"))
+	}
+	return customerIDs, nil
+}
+
+// customerAccounts percorre a hierarquia de contas e retorna as contas clientes (não gerenciadoras).
+// Com s.Config.ManagerID, apenas a hierarquia dessa conta gerenciadora é percorrida; sem ela, a de
+// cada conta acessível. Contas raiz que não puderem ser consultadas são devolvidas na lista de erros.
+func (s *GoogleAdsService) customerAccounts(accessToken string) ([]googleAdsAccount, []models.GoogleAdsItemError, error) {
+	roots := []string{normalizeGoogleCustomerID(s.Config.ManagerID)}
+	if roots[0] == "" {
+		var err error
+		roots, err = s.listAccessibleCustomers(accessToken)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(roots) == 0 {
+		return nil, nil, errors.New("não foram encontradas contas acessíveis para este token")
+	}
+
+	// O customer_client de uma conta retorna a própria conta (nível 0) e todas as contas abaixo dela
+	query, err := newGAQLQuery("customer_client").
+		Select("customer_client.id", "customer_client.descriptive_name", "customer_client.currency_code", "customer_client.manager", "customer_client.level").
+		Where("customer_client.status", "=", "ENABLED").
+		Build()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	results := make([][]googleAdsRow, len(roots))
+	errs := make([]error, len(roots))
+	forEachLimit(len(roots), s.concurrency(), func(i int) {
+		results[i], errs[i] = s.searchAs(accessToken, roots[i], roots[i], query)
+	})
+
+	accounts := []googleAdsAccount{}
+	itemErrors := []models.GoogleAdsItemError{}
+	seen := make(map[string]bool)
+	for i, rows := range results {
+		if errs[i] != nil {
+			log.Printf("Erro ao percorrer a hierarquia da conta %s: %v", roots[i], errs[i])
+			itemErrors = append(itemErrors, googleItemError(roots[i], "", errs[i]))
+			continue
+		}
+
+		for _, row := range rows {
+			client := row.CustomerClient
+			id := strconv.FormatInt(int64(client.ID), 10)
+			// Contas gerenciadoras não têm métricas; contas presentes em mais de uma hierarquia são consultadas uma vez
+			if client.Manager || client.ID == 0 || seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			accounts = append(accounts, googleAdsAccount{
+				ID:              id,
+				Nome:            client.DescriptiveName,
+				Moeda:           client.CurrencyCode,
+				LoginCustomerID: roots[i],
+			})
+		}
+	}
+
+	sort.SliceStable(accounts, func(i, j int) bool {
+		return accounts[i].Nome < accounts[j].Nome
+	})
+
+	return accounts, itemErrors, nil
+}
+
+// GetConsolidatedCampaignData obtém os dados de todas as contas clientes da hierarquia e de suas campanhas
+// no período de opts, com até s.Concurrency contas consultadas simultaneamente. Cada conta é seguida das suas
+// campanhas, com nome e moeda da conta; com opts.Segment, contas e campanhas têm uma linha por período.
+// Contas que falharem são devolvidas na lista de erros sem interromper as demais.
+func (s *GoogleAdsService) GetConsolidatedCampaignData(clientID, clientSecret, refreshToken string, opts GoogleAdsReportOptions) ([]*models.GoogleAdsData, []models.GoogleAdsItemError, error) {
+	if clientID == "" || clientSecret == "" || refreshToken == "" {
+		return nil, nil, errors.New("credenciais incompletas fornecidas")
+	}
+
+	query, err := googleAdsCampaignsQuery(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Configurar o serviço com as credenciais fornecidas
+	s.Config.ClientID = clientID
+	s.Config.ClientSecret = clientSecret
+
+	// O token de acesso é obtido uma vez e usado em todas as consultas
+	token, err := s.RefreshAccessToken(refreshToken)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao atualizar token de acesso: %w", err)
+	}
+
+	accounts, itemErrors, err := s.customerAccounts(token.AccessToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	log.Printf("Encontradas %d contas clientes do Google Ads", len(accounts))
+
+	type accountResult struct {
+		rows []*models.GoogleAdsData
+		err  error
+	}
+	results := make([]accountResult, len(accounts))
+
+	forEachLimit(len(accounts), s.concurrency(), func(i int) {
+		account := accounts[i]
+
+		// A linha da conta soma as métricas de todas as suas campanhas (por período, com segmentação)
+		totals := newGoogleAdsPeriods()
+		campaigns := []*models.GoogleAdsData{}
+		conversions, err := s.conversionsByAction(token.AccessToken, account.LoginCustomerID, account.ID, campaignConversionsQuery(""), opts, opts.conversionKey)
+		if err != nil {
+			results[i].err = err
+			return
+		}
+		err = s.streamAs(token.AccessToken, account.LoginCustomerID, account.ID, query, func(row googleAdsRow) error {
+			if row.Campaign.ID == 0 {
+				return nil
+			}
+			period := opts.period(row)
+			metrics := conversions.metrics(row, opts.conversionKey)
+			totals.add(period, metrics)
+
+			campaign := newGoogleAdsData(strconv.FormatInt(int64(row.Campaign.ID), 10), row.Campaign.Name, metrics)
+			campaign.Periodo = period
+			account.tag(&campaign, "campanha")
+			campaigns = append(campaigns, &campaign)
+			return nil
+		})
+		if err != nil {
+			results[i].err = err
+			return
+		}
+
+		// Sem segmentação, a conta tem sempre uma linha, mesmo sem campanhas no período
+		if len(totals.order) == 0 && opts.Segment == "" {
+			totals.add("", googleAdsMetrics{})
+		}
+		for _, accountData := range totals.data(account.ID, account.Nome) {
+			account.tag(&accountData, "conta")
+			results[i].rows = append(results[i].rows, &accountData)
+		}
+		results[i].rows = append(results[i].rows, campaigns...)
+	})
+
+	consolidated := []*models.GoogleAdsData{}
+	for i, result := range results {
+		if result.err != nil {
+			log.Printf("Erro ao obter campanhas da conta %s: %v", accounts[i].ID, result.err)
+			itemErrors = append(itemErrors, googleItemError(accounts[i].ID, accounts[i].Nome, result.err))
+			continue
+		}
+		consolidated = append(consolidated, result.rows...)
+	}
+
+	log.Printf("Dados consolidados do Google Ads: %d itens, %d erros", len(consolidated), len(itemErrors))
+
+	return consolidated, itemErrors, nil
+}
+
+// tag identifica a linha com o tipo e com o ID, o nome e a moeda da conta
+func (a googleAdsAccount) tag(data *models.GoogleAdsData, tipo string) {
+	data.Tipo = tipo
+	data.ContaID = a.ID
+	data.ContaNome = a.Nome
+	data.Moeda = a.Moeda
+}
+
+// concurrency retorna o número máximo de consultas simultâneas do serviço
+func (s *GoogleAdsService) concurrency() int {
+	if s.Concurrency <= 0 {
+		return DefaultGoogleAdsConcurrency
+	}
+	return s.Concurrency
+}
+
+// googleItemError descreve uma conta cujos dados não puderam ser obtidos
+func googleItemError(id string, nome string, err error) models.GoogleAdsItemError {
+	return models.GoogleAdsItemError{
+		Tipo:  "conta",
+		ID:    id,
+		Nome:  nome,
+		Error: extractErrorInfoFromGoogleAds(err),
+	}
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"poc-integracoes-onm/models"
+)
+
+// googleAdsBreakdowns traduz os breakdowns aceitos pela nossa API para os campos GAQL que segmentam as linhas
+var googleAdsBreakdowns = map[string][]string{
+	"device":          {"segments.device"},
+	"ad_network_type": {"segments.ad_network_type"},
+	"geo":             {"geographic_view.country_criterion_id", "geographic_view.location_type", "segments.geo_target_region"},
+	"hour":            {"segments.hour"},
+}
+
+// googleAdsGeographicView contém os campos de geographic_view usados no breakdown geo
+type googleAdsGeographicView struct {
+	CountryCriterionID googleAdsInt64 `json:"countryCriterionId"`
+	LocationType       string         `json:"locationType"`
+}
+
+// HasBreakdowns indica se a consulta deve retornar linhas segmentadas
+func (o GoogleAdsReportOptions) HasBreakdowns() bool {
+	return len(o.Breakdowns) > 0
+}
+
+// hasBreakdown indica se o breakdown foi solicitado
+func (o GoogleAdsReportOptions) hasBreakdown(breakdown string) bool {
+	return containsString(o.Breakdowns, breakdown)
+}
+
+// applyBreakdowns adiciona à consulta os campos dos breakdowns solicitados
+func (o GoogleAdsReportOptions) applyBreakdowns(q *gaqlQuery) *gaqlQuery {
+	for _, breakdown := range o.Breakdowns {
+		q.Select(googleAdsBreakdowns[breakdown]...)
+	}
+	return q
+}
+
+// segmentValues retorna os valores dos breakdowns da linha, indexados pelo nome do campo (ex: {"device": "MOBILE"})
+func (o GoogleAdsReportOptions) segmentValues(row googleAdsRow) map[string]string {
+	values := make(map[string]string)
+	for _, breakdown := range o.Breakdowns {
+		switch breakdown {
+		case "device":
+			values["device"] = row.Segments.Device
+		case "ad_network_type":
+			values["ad_network_type"] = row.Segments.AdNetworkType
+		case "geo":
+			values["country_criterion_id"] = googleAdsIDString(row.GeographicView.CountryCriterionID)
+			values["location_type"] = row.GeographicView.LocationType
+			values["geo_target_region"] = row.Segments.GeoTargetRegion
+		case "hour":
+			values["hour"] = strconv.FormatInt(int64(row.Segments.Hour), 10)
+		}
+	}
+	return values
+}
+
+// GetCampaignInsightsSegments obtém os insights de uma campanha segmentados pelos breakdowns informados
+func (s *GoogleAdsService) GetCampaignInsightsSegments(clientID, clientSecret, refreshToken, accountID, campaignID string, opts GoogleAdsReportOptions) ([]models.GoogleAdsSegment, error) {
+	if campaignID == "" {
+		return nil, errors.New("ID da campanha não fornecido")
+	}
+
+	query := func() *gaqlQuery {
+		return newGAQLQuery(opts.breakdownResource("campaign")).
+			Select("campaign.id", "campaign.name").
+			WhereID("campaign.id", campaignID)
+	}
+
+	return s.insightsSegments(clientID, clientSecret, refreshToken, accountID, query, opts, func(row googleAdsRow) (string, string) {
+		return campaignID, row.Campaign.Name
+	})
+}
+
+// GetAccountInsightsSegments obtém os insights da conta de anúncios segmentados pelos breakdowns informados
+func (s *GoogleAdsService) GetAccountInsightsSegments(clientID, clientSecret, refreshToken, accountID string, opts GoogleAdsReportOptions) ([]models.GoogleAdsSegment, error) {
+	query := func() *gaqlQuery {
+		return newGAQLQuery(opts.breakdownResource("customer")).
+			Select("customer.id", "customer.descriptive_name")
+	}
+
+	return s.insightsSegments(clientID, clientSecret, refreshToken, accountID, query, opts, func(row googleAdsRow) (string, string) {
+		nome := row.Customer.DescriptiveName
+		if nome == "" {
+			nome = "Conta " + accountID
+		}
+		return normalizeGoogleCustomerID(accountID), nome
+	})
+}
+
+// breakdownResource retorna o recurso da consulta: o breakdown geo só está disponível em geographic_view
+func (o GoogleAdsReportOptions) breakdownResource(resource string) string {
+	if o.hasBreakdown("geo") {
+		return "geographic_view"
+	}
+	return resource
+}
+
+// insightsSegments executa a consulta com os breakdowns e o período de opts e soma as linhas com os mesmos
+// valores de segmento (e período); query monta a consulta base (recurso, campos de identificação e filtros)
+// e identify retorna o ID e o nome da campanha ou conta de cada linha
+func (s *GoogleAdsService) insightsSegments(clientID, clientSecret, refreshToken, accountID string, query func() *gaqlQuery, opts GoogleAdsReportOptions, identify func(row googleAdsRow) (string, string)) ([]models.GoogleAdsSegment, error) {
+	if !opts.HasBreakdowns() {
+		return nil, errors.New("nenhum breakdown informado")
+	}
+
+	gaql, err := opts.apply(opts.applyBreakdowns(query().Select(googleAdsMetricsFields...))).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := s.reportToken(clientID, clientSecret, refreshToken, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	// As linhas de várias campanhas (ou localizações) com os mesmos segmentos são somadas
+	key := func(row googleAdsRow) string {
+		return opts.period(row) + "|" + segmentKey(opts.segmentValues(row))
+	}
+
+	conversions, err := s.conversionsByAction(accessToken, s.Config.ManagerID, accountID, opts.applyBreakdowns(query()), opts, key)
+	if err != nil {
+		return nil, err
+	}
+
+	var order []string
+	segments := make(map[string]*models.GoogleAdsSegment)
+	metrics := make(map[string]*googleAdsMetrics)
+
+	err = s.streamAs(accessToken, s.Config.ManagerID, accountID, gaql, func(row googleAdsRow) error {
+		rowKey := key(row)
+		if _, ok := segments[rowKey]; !ok {
+			id, nome := identify(row)
+			segments[rowKey] = &models.GoogleAdsSegment{
+				Segmento:      opts.segmentValues(row),
+				GoogleAdsData: models.GoogleAdsData{ID: id, Nome: nome, Periodo: opts.period(row)},
+			}
+			metrics[rowKey] = &googleAdsMetrics{}
+			order = append(order, rowKey)
+		}
+		metrics[rowKey].add(row.Metrics)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.GoogleAdsSegment, 0, len(order))
+	for _, rowKey := range order {
+		// Com ações de conversão escolhidas, as conversões vêm da consulta por ação
+		if conversions != nil {
+			metrics[rowKey].setConversions(conversions[rowKey])
+		}
+
+		segment := segments[rowKey]
+		data := newGoogleAdsData(segment.ID, segment.Nome, *metrics[rowKey])
+		data.Periodo = segment.Periodo
+		segment.GoogleAdsData = data
+		result = append(result, *segment)
+	}
+
+	return result, nil
+}
+
+// segmentKey monta uma chave estável com os valores dos segmentos
+func segmentKey(values map[string]string) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, key+"="+values[key])
+	}
+	return strings.Join(parts, "|")
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// GoogleAdsAPIVersion é a versão da API REST do Google Ads usada em todas as chamadas
+const GoogleAdsAPIVersion = "v19"
+
+// googleAdsAPIBaseURL é o endereço base da API REST do Google Ads
+const googleAdsAPIBaseURL = "https://googleads.googleapis.com"
+
+// ErrMissingDeveloperToken indica que o developer token do Google Ads não foi configurado
+var ErrMissingDeveloperToken = errors.New("developer token não encontrado. Configure a variável de ambiente GOOGLE_ADS_DEVELOPER_TOKEN")
+
+// googleDeveloperToken lê o developer token da variável de ambiente GOOGLE_ADS_DEVELOPER_TOKEN
+// ou, por compatibilidade, de GOOGLE_DEVELOPER_TOKEN
+func googleDeveloperToken() string {
+	if developerToken := os.Getenv("GOOGLE_ADS_DEVELOPER_TOKEN"); developerToken != "" {
+		return developerToken
+	}
+	return os.Getenv("GOOGLE_DEVELOPER_TOKEN")
+}
+
+// newRequest cria uma requisição para a API do Google Ads na versão GoogleAdsAPIVersion, com o token de
+// acesso, o developer token configurado e, quando houver conta gerenciadora, o cabeçalho login-customer-id.
+// O path é relativo à versão (ex: "/customers:listAccessibleCustomers"); body, se informado, é enviado como JSON.
+func (s *GoogleAdsService) newRequest(method, path, accessToken string, body interface{}) (*http.Request, error) {
+	return s.newRequestAs(method, path, accessToken, s.Config.ManagerID, body)
+}
+
+// newRequestAs cria a requisição como newRequest, mas usando loginCustomerID como login-customer-id;
+// usado ao percorrer contas de hierarquias diferentes em paralelo
+func (s *GoogleAdsService) newRequestAs(method, path, accessToken, loginCustomerID string, body interface{}) (*http.Request, error) {
+	if s.Config.DeveloperToken == "" {
+		return nil, ErrMissingDeveloperToken
+	}
+
+	var reader io.Reader
+	if body != nil {
+		payloadBytes, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao serializar payload: %w", err)
+		}
+		reader = bytes.NewReader(payloadBytes)
+	}
+
+	req, err := http.NewRequest(method, googleAdsAPIBaseURL+"/"+GoogleAdsAPIVersion+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar requisição: %w", err)
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("developer-token", s.Config.DeveloperToken)
+	if loginCustomerID = normalizeGoogleCustomerID(loginCustomerID); loginCustomerID != "" {
+		req.Header.Set("login-customer-id", loginCustomerID)
+	}
+
+	return req, nil
+}
+
+// do envia uma requisição criada por newRequest e retorna erro com o corpo da resposta quando o status não é 200.
+// Em caso de sucesso, cabe ao chamador fechar o corpo da resposta.
+func (s *GoogleAdsService) do(req *http.Request) (*http.Response, error) {
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao enviar requisição: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API do Google Ads retornou erro: status %d - %s", resp.StatusCode, string(body))
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// googleAdsConversions contém as métricas de conversão das ações escolhidas, indexadas pela chave da linha
+// (ver conversionKey); nil quando as conversões padrão da conta são usadas
+type googleAdsConversions map[string]googleAdsMetrics
+
+// conversionKey identifica a linha pela campanha (0 nas consultas de conta) e pelo período
+func (o GoogleAdsReportOptions) conversionKey(row googleAdsRow) string {
+	return googleAdsIDString(row.Campaign.ID) + "|" + o.period(row)
+}
+
+// metrics retorna as métricas da linha com as conversões substituídas pelas das ações escolhidas, usando a mesma
+// chave da consulta por ação; linhas sem conversões dessas ações ficam com as conversões zeradas
+func (c googleAdsConversions) metrics(row googleAdsRow, key func(row googleAdsRow) string) googleAdsMetrics {
+	metrics := row.Metrics
+	if c != nil {
+		metrics.setConversions(c[key(row)])
+	}
+	return metrics
+}
+
+// conversionActionNames monta os nomes de recurso das ações de conversão da conta (customers/{id}/conversionActions/{id})
+func (o GoogleAdsReportOptions) conversionActionNames(accountID string) []string {
+	names := make([]string, 0, len(o.ConversionActions))
+	for _, action := range o.ConversionActions {
+		names = append(names, "customers/"+normalizeGoogleCustomerID(accountID)+"/conversionActions/"+strings.TrimSpace(action))
+	}
+	return names
+}
+
+// conversionsByAction consulta as métricas de conversão apenas das ações em opts.ConversionActions, no mesmo
+// recurso, filtros e período da consulta principal, somadas pela chave que key atribui a cada linha. As métricas
+// de conversão segmentadas por ação não podem ser combinadas com cliques, impressões e custo, por isso são obtidas
+// em uma consulta separada. Sem ações escolhidas, retorna nil sem consultar a API.
+func (s *GoogleAdsService) conversionsByAction(accessToken, loginCustomerID, accountID string, query *gaqlQuery, opts GoogleAdsReportOptions, key func(row googleAdsRow) string) (googleAdsConversions, error) {
+	if len(opts.ConversionActions) == 0 {
+		return nil, nil
+	}
+
+	// Segmentos usados no WHERE também precisam estar no SELECT; as linhas de cada ação são somadas por key
+	gaql, err := opts.apply(query.
+		Select(googleAdsConversionFields...).
+		Select("segments.conversion_action").
+		Where("segments.conversion_action", "IN", opts.conversionActionNames(accountID))).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+
+	conversions := googleAdsConversions{}
+	err = s.streamAs(accessToken, loginCustomerID, accountID, gaql, func(row googleAdsRow) error {
+		total := conversions[key(row)]
+		total.add(row.Metrics)
+		conversions[key(row)] = total
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter conversões das ações %s: %w", strings.Join(opts.ConversionActions, ", "), err)
+	}
+
+	return conversions, nil
+}
+
+// campaignConversionsQuery é a consulta base das conversões por ação das campanhas; com campaignID, de uma só campanha
+func campaignConversionsQuery(campaignID string) *gaqlQuery {
+	query := newGAQLQuery("campaign").Select("campaign.id")
+	if campaignID != "" {
+		query.WhereID("campaign.id", campaignID)
+	}
+	return query
+}
+
+// accountConversionsQuery é a consulta base das conversões por ação da conta
+func accountConversionsQuery() *gaqlQuery {
+	return newGAQLQuery("customer").Select("customer.id")
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"poc-integracoes-onm/models"
+)
+
+// defaultGoogleAdsDateRange é o período usado quando nenhum intervalo é informado
+const defaultGoogleAdsDateRange = "LAST_30_DAYS"
+
+// googleAdsSegments traduz a segmentação temporal aceita pela nossa API para o campo de segmento do GAQL
+var googleAdsSegments = map[string]string{
+	"date":  "segments.date",
+	"week":  "segments.week",
+	"month": "segments.month",
+}
+
+// GoogleAdsReportOptions define o período e a segmentação temporal das consultas de desempenho do Google Ads
+type GoogleAdsReportOptions struct {
+	DateRange string // Qualquer período predefinido do GAQL (ex: LAST_7_DAYS, LAST_MONTH); padrão LAST_30_DAYS
+	Since     string // Data inicial (AAAA-MM-DD); exige Until
+	Until     string // Data final (AAAA-MM-DD); exige Since
+	Segment   string // date, week ou month; retorna uma linha por período
+
+	Breakdowns []string // device, ad_network_type, geo e/ou hour; retorna uma linha por combinação de segmentos
+
+	ConversionActions []string // IDs das ações de conversão contadas nas métricas de conversão; padrão: as incluídas em conversões na conta
+}
+
+// Validate verifica se o período e a segmentação informados são aceitos pelo GAQL
+func (o GoogleAdsReportOptions) Validate() error {
+	if o.DateRange != "" && !gaqlDateLiterals[strings.ToUpper(o.DateRange)] {
+		return fmt.Errorf("%w: date_range inválido: %s (use um período do GAQL, ex: LAST_7_DAYS, LAST_MONTH)", ErrInvalidGAQLQuery, o.DateRange)
+	}
+
+	if (o.Since == "") != (o.Until == "") {
+		return fmt.Errorf("%w: since e until devem ser informados juntos", ErrInvalidGAQLQuery)
+	}
+
+	if o.Since != "" {
+		since, until, err := o.dates()
+		if err != nil {
+			return err
+		}
+		if until.Before(since) {
+			return fmt.Errorf("%w: until deve ser igual ou posterior a since", ErrInvalidGAQLQuery)
+		}
+	}
+
+	if o.Segment != "" && googleAdsSegments[o.Segment] == "" {
+		return fmt.Errorf("%w: segment inválido: %s (use date, week ou month)", ErrInvalidGAQLQuery, o.Segment)
+	}
+
+	for _, breakdown := range o.Breakdowns {
+		if googleAdsBreakdowns[breakdown] == nil {
+			return fmt.Errorf("%w: breakdown inválido: %s (use device, ad_network_type, geo ou hour)", ErrInvalidGAQLQuery, breakdown)
+		}
+	}
+
+	for _, action := range o.ConversionActions {
+		if id, err := strconv.ParseInt(action, 10, 64); err != nil || id <= 0 {
+			return fmt.Errorf("%w: ação de conversão inválida: %s (use o ID numérico da ação)", ErrInvalidGAQLQuery, action)
+		}
+	}
+
+	return nil
+}
+
+// dates converte since e until em datas
+func (o GoogleAdsReportOptions) dates() (time.Time, time.Time, error) {
+	since, err := time.Parse(gaqlDateLayout, o.Since)
+	if err != nil {
+		return since, since, fmt.Errorf("%w: since inválido (use AAAA-MM-DD): %s", ErrInvalidGAQLQuery, o.Since)
+	}
+	until, err := time.Parse(gaqlDateLayout, o.Until)
+	if err != nil {
+		return since, until, fmt.Errorf("%w: until inválido (use AAAA-MM-DD): %s", ErrInvalidGAQLQuery, o.Until)
+	}
+	return since, until, nil
+}
+
+// apply adiciona à consulta o período (um intervalo since/until tem precedência sobre o date_range)
+// e, com segmentação, o campo do segmento e a ordenação por período
+func (o GoogleAdsReportOptions) apply(q *gaqlQuery) *gaqlQuery {
+	if segment := googleAdsSegments[o.Segment]; segment != "" {
+		q.Select(segment).OrderBy(segment, false)
+	}
+
+	if o.Since != "" && o.Until != "" {
+		since, until, err := o.dates()
+		if err != nil {
+			if q.err == nil {
+				q.err = err
+			}
+			return q
+		}
+		return q.Between(since, until)
+	}
+
+	dateRange := strings.ToUpper(o.DateRange)
+	if dateRange == "" {
+		dateRange = defaultGoogleAdsDateRange
+	}
+	return q.During(dateRange)
+}
+
+// period retorna o período da linha na segmentação escolhida, ou vazio sem segmentação
+func (o GoogleAdsReportOptions) period(row googleAdsRow) string {
+	switch o.Segment {
+	case "date":
+		return row.Segments.Date
+	case "week":
+		return row.Segments.Week
+	case "month":
+		return row.Segments.Month
+	}
+	return ""
+}
+
+// googleAdsPeriods soma as métricas das linhas por período, mantendo a ordem em que os períodos aparecem
+type googleAdsPeriods struct {
+	order   []string
+	metrics map[string]*googleAdsMetrics
+}
+
+// newGoogleAdsPeriods cria um acumulador de métricas por período vazio
+func newGoogleAdsPeriods() *googleAdsPeriods {
+	return &googleAdsPeriods{metrics: make(map[string]*googleAdsMetrics)}
+}
+
+// add soma as métricas de uma linha ao período
+func (p *googleAdsPeriods) add(period string, metrics googleAdsMetrics) {
+	total, ok := p.metrics[period]
+	if !ok {
+		total = &googleAdsMetrics{}
+		p.metrics[period] = total
+		p.order = append(p.order, period)
+	}
+	total.add(metrics)
+}
+
+// data monta uma linha de dados por período, na ordem dos períodos
+func (p *googleAdsPeriods) data(id, nome string) []models.GoogleAdsData {
+	rows := make([]models.GoogleAdsData, 0, len(p.order))
+	for _, period := range p.order {
+		data := newGoogleAdsData(id, nome, *p.metrics[period])
+		data.Periodo = period
+		rows = append(rows, data)
+	}
+	return rows
+}
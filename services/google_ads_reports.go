@@ -0,0 +1,302 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"poc-integracoes-onm/models"
+)
+
+// googleAdsAdGroup contém os campos de ad_group usados nos relatórios
+type googleAdsAdGroup struct {
+	ID     googleAdsInt64 `json:"id"`
+	Name   string         `json:"name"`
+	Status string         `json:"status"`
+}
+
+// googleAdsAdGroupAd contém os campos de ad_group_ad usados no relatório de anúncios
+type googleAdsAdGroupAd struct {
+	Status        string                 `json:"status"`
+	Ad            googleAdsAd            `json:"ad"`
+	PolicySummary googleAdsPolicySummary `json:"policySummary"`
+}
+
+// googleAdsAd contém os campos do anúncio, incluindo os textos do anúncio responsivo de pesquisa
+type googleAdsAd struct {
+	ID                 googleAdsInt64              `json:"id"`
+	Name               string                      `json:"name"`
+	FinalURLs          []string                    `json:"finalUrls"`
+	ResponsiveSearchAd googleAdsResponsiveSearchAd `json:"responsiveSearchAd"`
+}
+
+// googleAdsResponsiveSearchAd contém os títulos e as descrições de um anúncio responsivo de pesquisa
+type googleAdsResponsiveSearchAd struct {
+	Headlines    []googleAdsTextAsset `json:"headlines"`
+	Descriptions []googleAdsTextAsset `json:"descriptions"`
+}
+
+// googleAdsTextAsset é um texto de anúncio (título ou descrição)
+type googleAdsTextAsset struct {
+	Text string `json:"text"`
+}
+
+// googleAdsPolicySummary contém o status de aprovação do anúncio
+type googleAdsPolicySummary struct {
+	ApprovalStatus string `json:"approvalStatus"`
+}
+
+// googleAdsAdGroupCriterion contém os campos de ad_group_criterion usados no relatório de palavras-chave
+type googleAdsAdGroupCriterion struct {
+	CriterionID googleAdsInt64       `json:"criterionId"`
+	Status      string               `json:"status"`
+	Keyword     googleAdsKeyword     `json:"keyword"`
+	QualityInfo googleAdsQualityInfo `json:"qualityInfo"`
+}
+
+// googleAdsKeyword contém o texto e o tipo de correspondência da palavra-chave
+type googleAdsKeyword struct {
+	Text      string `json:"text"`
+	MatchType string `json:"matchType"`
+}
+
+// googleAdsQualityInfo contém o índice de qualidade e seus componentes; qualityScore é omitido pela API
+// quando não há dados suficientes para calculá-lo
+type googleAdsQualityInfo struct {
+	QualityScore          *googleAdsInt64 `json:"qualityScore"`
+	CreativeQualityScore  string          `json:"creativeQualityScore"`
+	PostClickQualityScore string          `json:"postClickQualityScore"`
+	SearchPredictedCtr    string          `json:"searchPredictedCtr"`
+}
+
+// ListAdGroups lista os grupos de anúncios da campanha com status e métricas do período de opts;
+// com opts.Segment, cada grupo tem uma linha por período
+func (s *GoogleAdsService) ListAdGroups(clientID, clientSecret, refreshToken, accountID, campaignID string, opts GoogleAdsReportOptions) ([]models.GoogleAdsAdGroupData, error) {
+	if campaignID == "" {
+		return nil, errors.New("ID da campanha não fornecido")
+	}
+
+	// Consulta base, compartilhada com a consulta das conversões por ação
+	base := func() *gaqlQuery {
+		return newGAQLQuery("ad_group").
+			Select("campaign.id", "ad_group.id").
+			WhereID("campaign.id", campaignID).
+			Where("ad_group.status", "!=", "REMOVED")
+	}
+	query := opts.apply(base().
+		Select("campaign.name", "ad_group.name", "ad_group.status").
+		Select(googleAdsMetricsFields...))
+	key := func(row googleAdsRow) string {
+		return googleAdsIDString(row.AdGroup.ID) + "|" + opts.period(row)
+	}
+
+	adGroups := []models.GoogleAdsAdGroupData{}
+	err := s.streamReport(clientID, clientSecret, refreshToken, accountID, query, base(), opts, key, func(row googleAdsRow, metrics googleAdsMetrics) error {
+		data := newGoogleAdsData(googleAdsIDString(row.AdGroup.ID), row.AdGroup.Name, metrics)
+		data.Periodo = opts.period(row)
+
+		adGroups = append(adGroups, models.GoogleAdsAdGroupData{
+			GoogleAdsData: data,
+			CampanhaID:    googleAdsIDString(row.Campaign.ID),
+			CampanhaNome:  row.Campaign.Name,
+			Status:        row.AdGroup.Status,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return adGroups, nil
+}
+
+// ListAds lista os anúncios responsivos de pesquisa da campanha ou do grupo de anúncios informado, com títulos,
+// descrições, status e métricas do período de opts; com opts.Segment, cada anúncio tem uma linha por período
+func (s *GoogleAdsService) ListAds(clientID, clientSecret, refreshToken, accountID, campaignID, adGroupID string, opts GoogleAdsReportOptions) ([]models.GoogleAdsAdData, error) {
+	base := func() (*gaqlQuery, error) {
+		return whereGoogleAdsParents(newGAQLQuery("ad_group_ad").
+			Select("campaign.id", "ad_group.id", "ad_group_ad.ad.id").
+			Where("ad_group_ad.ad.type", "=", "RESPONSIVE_SEARCH_AD").
+			Where("ad_group_ad.status", "!=", "REMOVED"), campaignID, adGroupID)
+	}
+	query, err := base()
+	if err != nil {
+		return nil, err
+	}
+	conversionsQuery, _ := base()
+
+	query = opts.apply(query.
+		Select("ad_group.name", "ad_group_ad.ad.name", "ad_group_ad.status").
+		Select("ad_group_ad.policy_summary.approval_status", "ad_group_ad.ad.final_urls").
+		Select("ad_group_ad.ad.responsive_search_ad.headlines", "ad_group_ad.ad.responsive_search_ad.descriptions").
+		Select(googleAdsMetricsFields...))
+	key := func(row googleAdsRow) string {
+		return googleAdsIDString(row.AdGroup.ID) + "|" + googleAdsIDString(row.AdGroupAd.Ad.ID) + "|" + opts.period(row)
+	}
+
+	ads := []models.GoogleAdsAdData{}
+	err = s.streamReport(clientID, clientSecret, refreshToken, accountID, query, conversionsQuery, opts, key, func(row googleAdsRow, metrics googleAdsMetrics) error {
+		ad := row.AdGroupAd.Ad
+		headlines := googleAdsTexts(ad.ResponsiveSearchAd.Headlines)
+		descriptions := googleAdsTexts(ad.ResponsiveSearchAd.Descriptions)
+
+		// Anúncios responsivos normalmente não têm nome; usar o primeiro título para identificá-los
+		nome := ad.Name
+		if nome == "" && len(headlines) > 0 {
+			nome = headlines[0]
+		}
+
+		data := newGoogleAdsData(googleAdsIDString(ad.ID), nome, metrics)
+		data.Periodo = opts.period(row)
+
+		ads = append(ads, models.GoogleAdsAdData{
+			GoogleAdsData:   data,
+			CampanhaID:      googleAdsIDString(row.Campaign.ID),
+			GrupoID:         googleAdsIDString(row.AdGroup.ID),
+			GrupoNome:       row.AdGroup.Name,
+			Status:          row.AdGroupAd.Status,
+			StatusAprovacao: row.AdGroupAd.PolicySummary.ApprovalStatus,
+			Titulos:         headlines,
+			Descricoes:      descriptions,
+			URLsFinais:      ad.FinalURLs,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ads, nil
+}
+
+// ListKeywords lista as palavras-chave da campanha ou do grupo de anúncios informado, com tipo de correspondência,
+// status, índice de qualidade e métricas do período de opts; com opts.Segment, cada palavra-chave tem uma linha
+// por período
+func (s *GoogleAdsService) ListKeywords(clientID, clientSecret, refreshToken, accountID, campaignID, adGroupID string, opts GoogleAdsReportOptions) ([]models.GoogleAdsKeywordData, error) {
+	base := func() (*gaqlQuery, error) {
+		return whereGoogleAdsParents(newGAQLQuery("keyword_view").
+			Select("campaign.id", "ad_group.id", "ad_group_criterion.criterion_id").
+			Where("ad_group_criterion.status", "!=", "REMOVED"), campaignID, adGroupID)
+	}
+	query, err := base()
+	if err != nil {
+		return nil, err
+	}
+	conversionsQuery, _ := base()
+
+	query = opts.apply(query.
+		Select("ad_group.name", "ad_group_criterion.status").
+		Select("ad_group_criterion.keyword.text", "ad_group_criterion.keyword.match_type").
+		Select("ad_group_criterion.quality_info.quality_score", "ad_group_criterion.quality_info.creative_quality_score").
+		Select("ad_group_criterion.quality_info.post_click_quality_score", "ad_group_criterion.quality_info.search_predicted_ctr").
+		Select(googleAdsMetricsFields...))
+	key := func(row googleAdsRow) string {
+		return googleAdsIDString(row.AdGroup.ID) + "|" + googleAdsIDString(row.AdGroupCriterion.CriterionID) + "|" + opts.period(row)
+	}
+
+	keywords := []models.GoogleAdsKeywordData{}
+	err = s.streamReport(clientID, clientSecret, refreshToken, accountID, query, conversionsQuery, opts, key, func(row googleAdsRow, metrics googleAdsMetrics) error {
+		criterion := row.AdGroupCriterion
+		data := newGoogleAdsData(googleAdsIDString(criterion.CriterionID), criterion.Keyword.Text, metrics)
+		data.Periodo = opts.period(row)
+
+		keyword := models.GoogleAdsKeywordData{
+			GoogleAdsData:            data,
+			CampanhaID:               googleAdsIDString(row.Campaign.ID),
+			GrupoID:                  googleAdsIDString(row.AdGroup.ID),
+			GrupoNome:                row.AdGroup.Name,
+			TipoCorrespondencia:      criterion.Keyword.MatchType,
+			Status:                   criterion.Status,
+			QualidadeAnuncio:         criterion.QualityInfo.CreativeQualityScore,
+			ExperienciaPaginaDestino: criterion.QualityInfo.PostClickQualityScore,
+			CTREsperada:              criterion.QualityInfo.SearchPredictedCtr,
+		}
+		if score := criterion.QualityInfo.QualityScore; score != nil {
+			value := int(*score)
+			keyword.IndiceQualidade = &value
+		}
+
+		keywords = append(keywords, keyword)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return keywords, nil
+}
+
+// streamReport valida as credenciais, obtém o token de acesso e executa a consulta no searchStream da conta,
+// chamando fn para cada linha à medida que chegam. Com ações de conversão em opts, as conversões por ação são
+// consultadas antes a partir de conversionsQuery (mesmo recurso e filtros da consulta, com os campos usados por key)
+// e fn recebe as métricas da linha com as conversões substituídas; sem elas, recebe as métricas da própria linha.
+func (s *GoogleAdsService) streamReport(clientID, clientSecret, refreshToken, accountID string, query, conversionsQuery *gaqlQuery, opts GoogleAdsReportOptions, key func(row googleAdsRow) string, fn func(row googleAdsRow, metrics googleAdsMetrics) error) error {
+	gaql, err := query.Build()
+	if err != nil {
+		return err
+	}
+
+	accessToken, err := s.reportToken(clientID, clientSecret, refreshToken, accountID)
+	if err != nil {
+		return err
+	}
+
+	conversions, err := s.conversionsByAction(accessToken, s.Config.ManagerID, accountID, conversionsQuery, opts, key)
+	if err != nil {
+		return err
+	}
+
+	return s.streamAs(accessToken, s.Config.ManagerID, accountID, gaql, func(row googleAdsRow) error {
+		return fn(row, conversions.metrics(row, key))
+	})
+}
+
+// reportToken valida as credenciais e a conta e obtém o token de acesso usado nas consultas dos relatórios
+func (s *GoogleAdsService) reportToken(clientID, clientSecret, refreshToken, accountID string) (string, error) {
+	if clientID == "" || clientSecret == "" || refreshToken == "" {
+		return "", errors.New("credenciais incompletas fornecidas")
+	}
+
+	if accountID == "" {
+		return "", errors.New("ID da conta não fornecido")
+	}
+
+	// Configurar o serviço com as credenciais fornecidas
+	s.Config.ClientID = clientID
+	s.Config.ClientSecret = clientSecret
+
+	// Obter token de acesso atualizado
+	token, err := s.RefreshAccessToken(refreshToken)
+	if err != nil {
+		return "", fmt.Errorf("erro ao atualizar token de acesso: %w", err)
+	}
+	return token.AccessToken, nil
+}
+
+// whereGoogleAdsParents filtra a consulta pela campanha e/ou pelo grupo de anúncios; ao menos um deve ser informado
+func whereGoogleAdsParents(query *gaqlQuery, campaignID, adGroupID string) (*gaqlQuery, error) {
+	if campaignID == "" && adGroupID == "" {
+		return nil, errors.New("ID da campanha ou do grupo de anúncios não fornecido")
+	}
+	if campaignID != "" {
+		query.WhereID("campaign.id", campaignID)
+	}
+	if adGroupID != "" {
+		query.WhereID("ad_group.id", adGroupID)
+	}
+	return query, nil
+}
+
+// googleAdsTexts extrai os textos de uma lista de títulos ou descrições
+func googleAdsTexts(assets []googleAdsTextAsset) []string {
+	texts := make([]string, 0, len(assets))
+	for _, asset := range assets {
+		texts = append(texts, asset.Text)
+	}
+	return texts
+}
+
+// googleAdsIDString formata um ID numérico da API para as respostas
+func googleAdsIDString(id googleAdsInt64) string {
+	return strconv.FormatInt(int64(id), 10)
+}
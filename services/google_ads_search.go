@@ -0,0 +1,419 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"poc-integracoes-onm/models"
+)
+
+// googleAdsInt64 decodifica os campos int64 da API REST do Google Ads, que chegam como strings JSON
+// (ex: "clicks": "123"); números JSON também são aceitos
+type googleAdsInt64 int64
+
+// UnmarshalJSON aceita "123", 123 e null
+func (v *googleAdsInt64) UnmarshalJSON(data []byte) error {
+	raw := strings.Trim(string(data), `"`)
+	if raw == "" || raw == "null" {
+		*v = 0
+		return nil
+	}
+
+	number, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return fmt.Errorf("valor int64 inválido na resposta do Google Ads: %s", data)
+	}
+	*v = googleAdsInt64(number)
+	return nil
+}
+
+// googleAdsSearchResponse representa a resposta do googleAds:search
+type googleAdsSearchResponse struct {
+	Results       []googleAdsRow `json:"results"`
+	NextPageToken string         `json:"nextPageToken"` // Vazio na última página
+}
+
+// googleAdsRow representa uma linha de resultado de uma consulta GAQL; a API REST usa camelCase nos campos
+type googleAdsRow struct {
+	Customer         googleAdsCustomer         `json:"customer"`
+	CustomerClient   googleAdsCustomerClient   `json:"customerClient"`
+	Campaign         googleAdsCampaign         `json:"campaign"`
+	AdGroup          googleAdsAdGroup          `json:"adGroup"`
+	AdGroupAd        googleAdsAdGroupAd        `json:"adGroupAd"`
+	AdGroupCriterion googleAdsAdGroupCriterion `json:"adGroupCriterion"`
+	SearchTermView   googleAdsSearchTermView   `json:"searchTermView"`
+	GeographicView   googleAdsGeographicView   `json:"geographicView"`
+	Metrics          googleAdsMetrics          `json:"metrics"`
+	Segments         googleAdsSegmentValues    `json:"segments"`
+}
+
+// googleAdsSegmentValues contém os segmentos temporais das linhas (datas no formato AAAA-MM-DD)
+type googleAdsSegmentValues struct {
+	Date  string `json:"date"`
+	Week  string `json:"week"`  // Segunda-feira que inicia a semana
+	Month string `json:"month"` // Primeiro dia do mês
+
+	Keyword             googleAdsSegmentKeyword `json:"keyword"`             // Palavra-chave que acionou o anúncio
+	SearchTermMatchType string                  `json:"searchTermMatchType"` // Como o termo de pesquisa correspondeu à palavra-chave
+
+	Device          string         `json:"device"`          // MOBILE, DESKTOP, TABLET, ...
+	AdNetworkType   string         `json:"adNetworkType"`   // SEARCH, SEARCH_PARTNERS, CONTENT, ...
+	Hour            googleAdsInt64 `json:"hour"`            // Hora do dia (0 a 23)
+	GeoTargetRegion string         `json:"geoTargetRegion"` // Nome do recurso da região (geoTargetConstants/...)
+}
+
+// googleAdsCustomer contém os campos de customer usados nas consultas
+type googleAdsCustomer struct {
+	ID              googleAdsInt64 `json:"id"`
+	DescriptiveName string         `json:"descriptiveName"`
+	CurrencyCode    string         `json:"currencyCode"`
+}
+
+// googleAdsCustomerClient contém os campos de customer_client usados para percorrer a hierarquia de contas
+type googleAdsCustomerClient struct {
+	ID              googleAdsInt64 `json:"id"`
+	DescriptiveName string         `json:"descriptiveName"`
+	CurrencyCode    string         `json:"currencyCode"`
+	Manager         bool           `json:"manager"`
+	Level           googleAdsInt64 `json:"level"`
+}
+
+// googleAdsCampaign contém os campos de campaign usados nas consultas
+type googleAdsCampaign struct {
+	ID     googleAdsInt64 `json:"id"`
+	Name   string         `json:"name"`
+	Status string         `json:"status"`
+}
+
+// googleAdsMetrics contém as métricas brutas retornadas pela API
+type googleAdsMetrics struct {
+	Clicks              googleAdsInt64 `json:"clicks"`
+	Impressions         googleAdsInt64 `json:"impressions"`
+	CostMicros          googleAdsInt64 `json:"costMicros"`
+	Conversions         float64        `json:"conversions"`
+	ConversionsValue    float64        `json:"conversionsValue"`
+	AllConversions      float64        `json:"allConversions"`
+	AllConversionsValue float64        `json:"allConversionsValue"`
+
+	// Parcelas de impressões (0 a 1); ausentes quando a API não as calcula para a linha
+	SearchImpressionShare           *float64 `json:"searchImpressionShare"`
+	SearchBudgetLostImpressionShare *float64 `json:"searchBudgetLostImpressionShare"`
+
+	// Impressões qualificadas estimadas e perdidas por orçamento das linhas somadas, usadas para ponderar
+	// as parcelas de impressões ao agregar várias linhas
+	searchImpressions float64
+	searchEligible    float64
+	searchBudgetLost  float64
+}
+
+// add soma as métricas de outra linha, para agregar resultados de várias linhas
+func (m *googleAdsMetrics) add(other googleAdsMetrics) {
+	m.Clicks += other.Clicks
+	m.Impressions += other.Impressions
+	m.CostMicros += other.CostMicros
+	m.Conversions += other.Conversions
+	m.ConversionsValue += other.ConversionsValue
+	m.AllConversions += other.AllConversions
+	m.AllConversionsValue += other.AllConversionsValue
+
+	// As parcelas não podem ser somadas: as impressões qualificadas de cada linha são estimadas
+	// (impressões / parcela) e as parcelas do total são recalculadas a partir delas
+	if other.searchEligible > 0 {
+		m.searchImpressions += other.searchImpressions
+		m.searchEligible += other.searchEligible
+		m.searchBudgetLost += other.searchBudgetLost
+	} else if share := other.SearchImpressionShare; share != nil && *share > 0 && other.Impressions > 0 {
+		eligible := float64(other.Impressions) / *share
+		m.searchImpressions += float64(other.Impressions)
+		m.searchEligible += eligible
+		if lost := other.SearchBudgetLostImpressionShare; lost != nil {
+			m.searchBudgetLost += *lost * eligible
+		}
+	}
+}
+
+// impressionShares retorna a parcela de impressões de pesquisa e a parcela perdida por orçamento (0 a 1),
+// recalculadas quando as métricas somam várias linhas; nil quando a API não as informou
+func (m googleAdsMetrics) impressionShares() (*float64, *float64) {
+	if m.searchEligible == 0 {
+		return m.SearchImpressionShare, m.SearchBudgetLostImpressionShare
+	}
+	share := m.searchImpressions / m.searchEligible
+	lost := m.searchBudgetLost / m.searchEligible
+	return &share, &lost
+}
+
+// setConversions substitui as métricas de conversão pelas de other (ex: apenas as ações de conversão escolhidas)
+func (m *googleAdsMetrics) setConversions(other googleAdsMetrics) {
+	m.Conversions = other.Conversions
+	m.ConversionsValue = other.ConversionsValue
+	m.AllConversions = other.AllConversions
+	m.AllConversionsValue = other.AllConversionsValue
+}
+
+// googleAdsConversionFields são as métricas de conversão, as únicas compatíveis com segments.conversion_action
+var googleAdsConversionFields = []string{"metrics.conversions", "metrics.conversions_value", "metrics.all_conversions", "metrics.all_conversions_value"}
+
+// googleAdsMetricsFields são as métricas solicitadas em todas as consultas de desempenho
+var googleAdsMetricsFields = append([]string{"metrics.clicks", "metrics.impressions", "metrics.cost_micros"}, googleAdsConversionFields...)
+
+// googleAdsImpressionShareFields são as parcelas de impressões de pesquisa, disponíveis apenas nas consultas
+// de contas e campanhas
+var googleAdsImpressionShareFields = []string{"metrics.search_impression_share", "metrics.search_budget_lost_impression_share"}
+
+// googleAdsCampaignsQuery monta a consulta das métricas de todas as campanhas da conta no período de opts
+func googleAdsCampaignsQuery(opts GoogleAdsReportOptions) (string, error) {
+	return opts.apply(newGAQLQuery("campaign").
+		Select("campaign.id", "campaign.name").
+		Select(googleAdsMetricsFields...).
+		Select(googleAdsImpressionShareFields...)).
+		Build()
+}
+
+// search executa uma consulta GAQL no googleAds:search da conta e decodifica as linhas de resultado de todas as páginas
+func (s *GoogleAdsService) search(accessToken, customerID, query string) ([]googleAdsRow, error) {
+	return s.searchAs(accessToken, s.Config.ManagerID, customerID, query)
+}
+
+// searchAs executa a consulta como search, acessando a conta por meio de loginCustomerID
+func (s *GoogleAdsService) searchAs(accessToken, loginCustomerID, customerID, query string) ([]googleAdsRow, error) {
+	rows := []googleAdsRow{}
+	err := s.searchEachAs(accessToken, loginCustomerID, customerID, query, func(row googleAdsRow) error {
+		rows = append(rows, row)
+		return nil
+	})
+	return rows, err
+}
+
+// searchEachAs executa a consulta no googleAds:search seguindo o nextPageToken até a última página
+// e chama fn para cada linha; um erro retornado por fn interrompe a consulta
+func (s *GoogleAdsService) searchEachAs(accessToken, loginCustomerID, customerID, query string, fn func(row googleAdsRow) error) error {
+	path := "/customers/" + normalizeGoogleCustomerID(customerID) + "/googleAds:search"
+	pageToken := ""
+
+	for {
+		payload := map[string]interface{}{"query": query}
+		if pageToken != "" {
+			payload["pageToken"] = pageToken
+		}
+
+		req, err := s.newRequestAs("POST", path, accessToken, loginCustomerID, payload)
+		if err != nil {
+			return err
+		}
+
+		resp, err := s.do(req)
+		if err != nil {
+			return err
+		}
+
+		var response googleAdsSearchResponse
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("erro ao decodificar resposta: %w", err)
+		}
+
+		for _, row := range response.Results {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+
+		if response.NextPageToken == "" {
+			return nil
+		}
+		pageToken = response.NextPageToken
+	}
+}
+
+// streamAs executa a consulta no googleAds:searchStream, acessando a conta por meio de loginCustomerID.
+// A resposta (um array JSON de lotes de resultados) é decodificada linha a linha à medida que chega,
+// sem carregar a resposta inteira em memória; fn é chamada para cada linha e um erro interrompe a leitura.
+func (s *GoogleAdsService) streamAs(accessToken, loginCustomerID, customerID, query string, fn func(row googleAdsRow) error) error {
+	req, err := s.newRequestAs("POST", "/customers/"+normalizeGoogleCustomerID(customerID)+"/googleAds:searchStream", accessToken, loginCustomerID, map[string]interface{}{"query": query})
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := decodeGoogleAdsStream(json.NewDecoder(resp.Body), fn); err != nil {
+		var streamErr *googleAdsStreamError
+		if errors.As(err, &streamErr) {
+			return err
+		}
+		return fmt.Errorf("erro ao decodificar resposta: %w", err)
+	}
+	return nil
+}
+
+// googleAdsStreamError é o erro que o searchStream envia no lugar de um lote quando a consulta falha depois
+// que a resposta (status 200) já começou a ser enviada
+type googleAdsStreamError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Status  string `json:"status"`
+}
+
+func (e *googleAdsStreamError) Error() string {
+	return fmt.Sprintf("API do Google Ads retornou erro durante o searchStream: %d %s - %s", e.Code, e.Status, e.Message)
+}
+
+// googleAdsStreamIgnoredFields são os campos dos lotes do searchStream que não são usados
+var googleAdsStreamIgnoredFields = map[string]bool{
+	"fieldMask":                true,
+	"requestId":                true,
+	"queryResourceConsumption": true,
+}
+
+// decodeGoogleAdsStream percorre o array de lotes do searchStream ([{"results": [...]}, ...]) e decodifica
+// cada linha de results individualmente. Um lote com error interrompe a leitura com esse erro, para que as
+// linhas já lidas não sejam tratadas como um relatório completo; fieldMask, requestId e queryResourceConsumption
+// são ignorados e qualquer outro campo é rejeitado.
+func decodeGoogleAdsStream(dec *json.Decoder, fn func(row googleAdsRow) error) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		if err := expectDelim(dec, '{'); err != nil {
+			return err
+		}
+
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+
+			switch name, _ := key.(string); {
+			case name == "error":
+				streamErr := &googleAdsStreamError{}
+				if err := dec.Decode(streamErr); err != nil {
+					return err
+				}
+				return streamErr
+			case googleAdsStreamIgnoredFields[name]:
+				var skip json.RawMessage
+				if err := dec.Decode(&skip); err != nil {
+					return err
+				}
+				continue
+			case name != "results":
+				return fmt.Errorf("campo inesperado na resposta do searchStream: %v", key)
+			}
+
+			if err := expectDelim(dec, '['); err != nil {
+				return err
+			}
+			for dec.More() {
+				var row googleAdsRow
+				if err := dec.Decode(&row); err != nil {
+					return err
+				}
+				if err := fn(row); err != nil {
+					return err
+				}
+			}
+			if err := expectDelim(dec, ']'); err != nil {
+				return err
+			}
+		}
+
+		if err := expectDelim(dec, '}'); err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(dec, ']')
+}
+
+// expectDelim lê o próximo token e verifica se é o delimitador esperado
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("esperado %q na resposta do searchStream, encontrado %v", delim, token)
+	}
+	return nil
+}
+
+// newGoogleAdsData monta os dados de uma campanha ou conta calculando as métricas derivadas
+// (CTR, CPC, taxa e custo por conversão, valor por conversão, ROAS e parcelas de impressões)
+// a partir das métricas brutas da API
+func newGoogleAdsData(id string, nome string, metrics googleAdsMetrics) models.GoogleAdsData {
+	custo := float64(metrics.CostMicros) / 1000000.0 // Converter micros para a moeda da conta
+	impressions := float64(metrics.Impressions)
+	clicks := float64(metrics.Clicks)
+
+	ctr := 0.0
+	if impressions > 0 {
+		ctr = clicks / impressions * 100.0
+	}
+	cpc := 0.0
+	if clicks > 0 {
+		cpc = custo / clicks
+	}
+	taxaConversao := 0.0
+	if clicks > 0 {
+		taxaConversao = metrics.Conversions / clicks * 100.0
+	}
+	custoConversao := 0.0
+	if metrics.Conversions > 0 {
+		custoConversao = custo / metrics.Conversions
+	}
+	valorPorConversao := 0.0
+	if metrics.Conversions > 0 {
+		valorPorConversao = metrics.ConversionsValue / metrics.Conversions
+	}
+	roas := 0.0
+	if custo > 0 {
+		roas = metrics.ConversionsValue / custo
+	}
+
+	data := models.GoogleAdsData{
+		ID:                id,
+		Nome:              nome,
+		CTR:               roundFloat(ctr, 2),
+		CPC:               roundFloat(cpc, 2),
+		Conversoes:        int(math.Round(metrics.Conversions)),
+		TaxaConversao:     roundFloat(taxaConversao, 2),
+		CustoConversao:    roundFloat(custoConversao, 2),
+		InvestimentoTotal: roundFloat(custo, 2),
+		Impressions:       int(metrics.Impressions),
+		Clicks:            int(metrics.Clicks),
+		ValorConversoes:   roundFloat(metrics.ConversionsValue, 2),
+		TodasConversoes:   int(math.Round(metrics.AllConversions)),
+		ValorPorConversao: roundFloat(valorPorConversao, 2),
+		ROAS:              roundFloat(roas, 2),
+	}
+
+	// Parcelas de impressões em porcentagem, como a CTR
+	share, lost := metrics.impressionShares()
+	if share != nil {
+		value := roundFloat(*share*100.0, 2)
+		data.ParcelaImpressoes = &value
+	}
+	if lost != nil {
+		value := roundFloat(*lost*100.0, 2)
+		data.ParcelaPerdidaOrcamento = &value
+	}
+
+	return data
+}
+
+// normalizeGoogleCustomerID remove os hífens do ID da conta (580-825-6042 -> 5808256042)
+func normalizeGoogleCustomerID(customerID string) string {
+	return strings.ReplaceAll(strings.TrimSpace(customerID), "-", "")
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"poc-integracoes-onm/models"
+)
+
+// googleAdsSearchTermView contém os campos de search_term_view usados no relatório de termos de pesquisa
+type googleAdsSearchTermView struct {
+	SearchTerm string `json:"searchTerm"`
+	Status     string `json:"status"`
+}
+
+// googleAdsSegmentKeyword contém a palavra-chave que acionou o anúncio (segments.keyword)
+type googleAdsSegmentKeyword struct {
+	Info googleAdsKeyword `json:"info"`
+}
+
+// googleAdsSearchTermsCSVHeader são as colunas do CSV de termos de pesquisa
+var googleAdsSearchTermsCSVHeader = []string{
+	"termo_pesquisa", "status_termo", "tipo_correspondencia_termo", "palavra_chave", "tipo_correspondencia",
+	"campanha_id", "campanha_nome", "grupo_id", "grupo_nome", "periodo",
+	"impressoes", "cliques", "ctr", "cpc", "investimento_total", "conversoes", "taxa_conversao", "custo_conversao",
+	"valor_conversoes", "roas",
+}
+
+// ListSearchTerms lista os termos de pesquisa que acionaram anúncios da conta, com a palavra-chave correspondente
+// e as métricas do período de opts, do maior para o menor custo. Com campaignID, apenas os termos da campanha são
+// retornados; com opts.Segment, cada termo tem uma linha por período.
+func (s *GoogleAdsService) ListSearchTerms(clientID, clientSecret, refreshToken, accountID, campaignID string, opts GoogleAdsReportOptions) ([]models.GoogleAdsSearchTermData, error) {
+	// Consulta base, compartilhada com a consulta das conversões por ação
+	base := func() *gaqlQuery {
+		query := newGAQLQuery("search_term_view").
+			Select("campaign.id", "ad_group.id", "search_term_view.search_term", "segments.search_term_match_type").
+			Select("segments.keyword.info.text", "segments.keyword.info.match_type")
+		if campaignID != "" {
+			query.WhereID("campaign.id", campaignID)
+		}
+		return query
+	}
+	query := opts.apply(base().
+		Select("search_term_view.status", "campaign.name", "ad_group.name").
+		Select(googleAdsMetricsFields...)).
+		OrderBy("metrics.cost_micros", true)
+	key := func(row googleAdsRow) string {
+		return strings.Join([]string{
+			googleAdsIDString(row.AdGroup.ID), row.SearchTermView.SearchTerm, row.Segments.SearchTermMatchType,
+			row.Segments.Keyword.Info.Text, row.Segments.Keyword.Info.MatchType, opts.period(row),
+		}, "|")
+	}
+
+	terms := []models.GoogleAdsSearchTermData{}
+	err := s.streamReport(clientID, clientSecret, refreshToken, accountID, query, base(), opts, key, func(row googleAdsRow, metrics googleAdsMetrics) error {
+		data := newGoogleAdsData("", row.SearchTermView.SearchTerm, metrics)
+		data.Periodo = opts.period(row)
+
+		terms = append(terms, models.GoogleAdsSearchTermData{
+			GoogleAdsData:            data,
+			StatusTermo:              row.SearchTermView.Status,
+			TipoCorrespondenciaTermo: row.Segments.SearchTermMatchType,
+			PalavraChave:             row.Segments.Keyword.Info.Text,
+			TipoCorrespondencia:      row.Segments.Keyword.Info.MatchType,
+			CampanhaID:               googleAdsIDString(row.Campaign.ID),
+			CampanhaNome:             row.Campaign.Name,
+			GrupoID:                  googleAdsIDString(row.AdGroup.ID),
+			GrupoNome:                row.AdGroup.Name,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return terms, nil
+}
+
+// WriteSearchTermsCSV escreve os termos de pesquisa em CSV, com uma linha de cabeçalho
+func WriteSearchTermsCSV(w io.Writer, terms []models.GoogleAdsSearchTermData) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(googleAdsSearchTermsCSVHeader); err != nil {
+		return err
+	}
+
+	for _, term := range terms {
+		record := []string{
+			csvText(term.Nome), term.StatusTermo, term.TipoCorrespondenciaTermo, csvText(term.PalavraChave), term.TipoCorrespondencia,
+			term.CampanhaID, csvText(term.CampanhaNome), term.GrupoID, csvText(term.GrupoNome), term.Periodo,
+			strconv.Itoa(term.Impressions), strconv.Itoa(term.Clicks), csvFloat(term.CTR), csvFloat(term.CPC),
+			csvFloat(term.InvestimentoTotal), strconv.Itoa(term.Conversoes), csvFloat(term.TaxaConversao), csvFloat(term.CustoConversao),
+			csvFloat(term.ValorConversoes), csvFloat(term.ROAS),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvText neutraliza textos livres (termos pesquisados, nomes) que uma planilha interpretaria como fórmula,
+// prefixando com ' as células que começam com =, +, -, @, tabulação ou retorno de carro
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// csvFloat formata um número decimal para o CSV, com ponto como separador decimal
+func csvFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,29 @@
+package services
+
+import "testing"
+
+func TestCSVText(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "vazio", value: "", want: ""},
+		{name: "texto comum", value: "tenis corrida", want: "tenis corrida"},
+		{name: "igual", value: "=HYPERLINK(\"http://x\")", want: "'=HYPERLINK(\"http://x\")"},
+		{name: "mais", value: "+55 11 99999", want: "'+55 11 99999"},
+		{name: "menos", value: "-2+3", want: "'-2+3"},
+		{name: "arroba", value: "@SUM(A1)", want: "'@SUM(A1)"},
+		{name: "tabulação", value: "\t=1", want: "'\t=1"},
+		{name: "retorno de carro", value: "\r=1", want: "'\r=1"},
+		{name: "sinal fora do início", value: "a=1", want: "a=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := csvText(tt.value); got != tt.want {
+				t.Errorf("csvText(%q) = %q, esperado %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
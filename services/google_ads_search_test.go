@@ -0,0 +1,110 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeGoogleAdsStream(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantIDs  []int64
+		wantErr  bool
+		apiError bool
+	}{
+		{
+			name:    "array vazio",
+			body:    `[]`,
+			wantIDs: nil,
+		},
+		{
+			name:    "um lote",
+			body:    `[{"results": [{"campaign": {"id": "1"}}, {"campaign": {"id": "2"}}], "fieldMask": "campaign.id", "requestId": "abc"}]`,
+			wantIDs: []int64{1, 2},
+		},
+		{
+			name: "vários lotes",
+			body: `[
+				{"results": [{"campaign": {"id": "1"}}], "fieldMask": "campaign.id", "requestId": "abc"},
+				{"results": [{"campaign": {"id": "2"}}, {"campaign": {"id": "3"}}], "fieldMask": "campaign.id", "requestId": "abc"},
+				{"fieldMask": "campaign.id", "requestId": "abc", "queryResourceConsumption": "42"}
+			]`,
+			wantIDs: []int64{1, 2, 3},
+		},
+		{
+			name:     "erro depois de um lote",
+			body:     `[{"results": [{"campaign": {"id": "1"}}]}, {"error": {"code": 429, "message": "Resource has been exhausted", "status": "RESOURCE_EXHAUSTED"}}]`,
+			wantIDs:  []int64{1},
+			wantErr:  true,
+			apiError: true,
+		},
+		{
+			name:    "resposta truncada no meio de um lote",
+			body:    `[{"results": [{"campaign": {"id": "1"}}, {"campaign": {"id": "2"`,
+			wantIDs: []int64{1},
+			wantErr: true,
+		},
+		{
+			name:    "resposta truncada entre lotes",
+			body:    `[{"results": [{"campaign": {"id": "1"}}]},`,
+			wantIDs: []int64{1},
+			wantErr: true,
+		},
+		{
+			name:    "resposta sem o fechamento do array",
+			body:    `[{"results": [{"campaign": {"id": "1"}}]}`,
+			wantIDs: []int64{1},
+			wantErr: true,
+		},
+		{
+			name:    "campo desconhecido no lote",
+			body:    `[{"summary": {}}]`,
+			wantErr: true,
+		},
+		{
+			name:    "objeto em vez de array",
+			body:    `{"results": []}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []int64
+			err := decodeGoogleAdsStream(json.NewDecoder(strings.NewReader(tt.body)), func(row googleAdsRow) error {
+				ids = append(ids, int64(row.Campaign.ID))
+				return nil
+			})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeGoogleAdsStream() erro = %v, esperado erro = %v", err, tt.wantErr)
+			}
+			var streamErr *googleAdsStreamError
+			if errors.As(err, &streamErr) != tt.apiError {
+				t.Errorf("decodeGoogleAdsStream() erro = %v, esperado erro da API = %v", err, tt.apiError)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("linhas lidas = %v, esperado %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestDecodeGoogleAdsStreamStopsOnCallbackError(t *testing.T) {
+	stop := errors.New("parar")
+	calls := 0
+	err := decodeGoogleAdsStream(json.NewDecoder(strings.NewReader(`[{"results": [{}, {}, {}]}]`)), func(row googleAdsRow) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf("decodeGoogleAdsStream() erro = %v, esperado %v", err, stop)
+	}
+	if calls != 1 {
+		t.Errorf("fn chamada %d vezes, esperado 1", calls)
+	}
+}
@@ -11,7 +11,7 @@ import (
 	"math"
 	"net/http"
 	"net/url"
-	"os"
+	"strconv"
 	"strings"
 
 	"poc-integracoes-onm/models"
@@ -24,27 +24,24 @@ type GoogleAdsConfig struct {
 	RedirectURI    string
 	State          string
 	DeveloperToken string
+	ManagerID      string // Conta gerenciadora (MCC) enviada como login-customer-id, se houver
 }
 
 // GoogleAdsService implementa o serviço para integração com o Google Ads
 type GoogleAdsService struct {
 	// Configurações do serviço
 	Config GoogleAdsConfig
+	// Número máximo de contas consultadas simultaneamente nos dados consolidados
+	Concurrency int
 }
 
 // NewGoogleAdsService cria uma nova instância do serviço Google Ads
 func NewGoogleAdsService() *GoogleAdsService {
-	// Tentar obter o developer token da variável de ambiente
-	developerToken := os.Getenv("GOOGLE_ADS_DEVELOPER_TOKEN")
-	if developerToken == "" {
-		// Tentar nome alternativo por compatibilidade
-		developerToken = os.Getenv("GOOGLE_DEVELOPER_TOKEN")
-	}
-
 	return &GoogleAdsService{
 		Config: GoogleAdsConfig{
-			DeveloperToken: developerToken,
+			DeveloperToken: googleDeveloperToken(),
 		},
+		Concurrency: googleAdsConcurrency(),
 	}
 }
 
@@ -56,8 +53,9 @@ func NewGoogleAdsServiceWithConfig(clientID, clientSecret, redirectURI, state st
 			ClientSecret:   clientSecret,
 			RedirectURI:    redirectURI,
 			State:          state,
-			DeveloperToken: os.Getenv("GOOGLE_DEVELOPER_TOKEN"),
+			DeveloperToken: googleDeveloperToken(),
 		},
+		Concurrency: googleAdsConcurrency(),
 	}
 }
 
@@ -175,41 +173,46 @@ func (s *GoogleAdsService) RefreshAccessToken(refreshToken string) (*models.OAut
 }
 
 // GetMetricas obtém as métricas principais do Google Ads usando as credenciais fornecidas
-func (s *GoogleAdsService) GetMetricas(clientID, clientSecret, refreshToken, managerID string) (*models.GoogleAdsData, error) {
-	if clientID == "" || clientSecret == "" || refreshToken == "" {
-		return nil, errors.New("credenciais incompletas fornecidas")
+func (s *GoogleAdsService) GetMetricas(clientID, clientSecret, refreshToken, managerID, accountID string, opts GoogleAdsReportOptions) (*models.GoogleAdsData, error) {
+	account, err := s.metricasAccount(clientID, clientSecret, refreshToken, managerID, accountID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Configurar o serviço com as credenciais fornecidas
-	s.Config.ClientID = clientID
-	s.Config.ClientSecret = clientSecret
-
-	// Obter token de acesso atualizado
-	_, err := s.RefreshAccessToken(refreshToken)
+	// Obter insights da conta
+	data, err := s.GetAccountInsights(clientID, clientSecret, refreshToken, account.ID, opts)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao atualizar token de acesso: %w", err)
+		return nil, err
 	}
+	account.tag(data, "conta")
+	return data, nil
+}
 
-	// Usar o ID da conta gerenciadora se fornecido, ou usar um ID padrão
-	accountID := managerID
-	if accountID == "" {
-		accountID = "5808256042" // ID padrão para a POC
+// GetMetricasSeries obtém as métricas principais do Google Ads como uma série temporal, um ponto por período de opts.Segment
+func (s *GoogleAdsService) GetMetricasSeries(clientID, clientSecret, refreshToken, managerID, accountID string, opts GoogleAdsReportOptions) ([]models.GoogleAdsData, error) {
+	account, err := s.metricasAccount(clientID, clientSecret, refreshToken, managerID, accountID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Obter insights da conta
-	return s.GetAccountInsights(clientID, clientSecret, refreshToken, accountID)
+	series, err := s.GetAccountInsightsSeries(clientID, clientSecret, refreshToken, account.ID, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range series {
+		account.tag(&series[i], "conta")
+	}
+	return series, nil
 }
 
-// GetCampaignInsights obtém insights detalhados de uma campanha específica
-func (s *GoogleAdsService) GetCampaignInsights(clientID, clientSecret, refreshToken, campaignID string) (*models.GoogleAdsData, error) {
+// metricasAccount retorna a conta cliente accountID da hierarquia (da conta gerenciadora, se fornecida) e passa a
+// acessá-la pela raiz da sua hierarquia. Sem accountID, usa a única conta cliente da hierarquia; com mais de uma,
+// retorna erro em vez de escolher uma delas
+func (s *GoogleAdsService) metricasAccount(clientID, clientSecret, refreshToken, managerID, accountID string) (*googleAdsAccount, error) {
 	if clientID == "" || clientSecret == "" || refreshToken == "" {
 		return nil, errors.New("credenciais incompletas fornecidas")
 	}
 
-	if campaignID == "" {
-		return nil, errors.New("ID da campanha não fornecido")
-	}
-
 	// Configurar o serviço com as credenciais fornecidas
 	s.Config.ClientID = clientID
 	s.Config.ClientSecret = clientSecret
@@ -220,113 +223,66 @@ func (s *GoogleAdsService) GetCampaignInsights(clientID, clientSecret, refreshTo
 		return nil, fmt.Errorf("erro ao atualizar token de acesso: %w", err)
 	}
 
-	// Construir a URL da API do Google Ads
-	// De acordo com a documentação oficial da API Google Ads V1:
-	// https://developers.google.com/google-ads/api/docs/rest/overview
-	// A URL correta para o serviço GoogleAdsService.Search é:
-	// POST https://googleads.googleapis.com/v1/customers/{customer_id}:search
-
-	// O ID correto da conta é 580-825-6042, mas para a API precisamos remover os hífens
-	customerID := "5808256042" // ID da conta formatado corretamente para a API
-
-	// Usando a versão v19 (mais estável e bem documentada)
-	apiURL := fmt.Sprintf("https://googleads.googleapis.com/v19/customers/%s:search", customerID)
-
-	// Registrar os detalhes da requisição
-	fmt.Printf("URL da API: %s\n", apiURL)
-
-	// Construir o payload da requisição (JSON)
-	payload := map[string]interface{}{
-		"query": fmt.Sprintf("SELECT campaign.id, campaign.name, metrics.clicks, metrics.impressions, metrics.ctr, metrics.average_cpc, metrics.cost_micros, metrics.conversions, metrics.cost_per_conversion FROM campaign WHERE campaign.id = %s AND segments.date DURING LAST_30_DAYS", campaignID),
+	// Listar as contas clientes da hierarquia (da conta gerenciadora, se fornecida)
+	if managerID != "" {
+		s.Config.ManagerID = managerID
 	}
-
-	// Converter o payload para JSON
-	payloadBytes, err := json.Marshal(payload)
+	accounts, _, err := s.customerAccounts(token.AccessToken)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao converter payload para JSON: %v", err)
+		return nil, err
 	}
-
-	// Registrar o payload da requisição
-	fmt.Printf("Payload: %s\n", string(payloadBytes))
-
-	// Criar a requisição HTTP
-	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(payloadBytes))
-	if err != nil {
-		return nil, fmt.Errorf("erro ao criar requisição HTTP: %v", err)
+	if len(accounts) == 0 {
+		return nil, errors.New("nenhuma conta cliente do Google Ads encontrada para este token")
 	}
 
-	// Adicionar cabeçalhos necessários
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
-
-	// Importante: Este token de desenvolvedor é necessário para acessar a API
-	req.Header.Set("developer-token", "d_pAjxizpi1whv22-_SYjA") // Token de desenvolvedor real
-
-	// Vamos remover o cabeçalho x-goog-user-project que pode estar causando confusão
-	// E não vamos incluir o cabeçalho x-goog-api-client por enquanto
+	var account *googleAdsAccount
+	if accountID = normalizeGoogleCustomerID(accountID); accountID != "" {
+		for i := range accounts {
+			if accounts[i].ID == accountID {
+				account = &accounts[i]
+				break
+			}
+		}
+		if account == nil {
+			return nil, fmt.Errorf("conta %s não encontrada entre as contas clientes acessíveis", accountID)
+		}
+	} else {
+		if len(accounts) > 1 {
+			ids := make([]string, 0, len(accounts))
+			for _, account := range accounts {
+				ids = append(ids, account.ID)
+			}
+			return nil, fmt.Errorf("há %d contas clientes acessíveis (%s); informe account_id", len(accounts), strings.Join(ids, ", "))
+		}
+		account = &accounts[0]
+	}
 
-	fmt.Printf("Headers:\n")
-	fmt.Printf("  Content-Type: application/json\n")
-	fmt.Printf("  Authorization: Bearer %s...\n", token.AccessToken[:15])
-	fmt.Printf("  Developer-token: %s...\n", "d_pAjxizpi1whv22-_SYjA"[:10])
+	// Acessar a conta pela raiz da sua hierarquia
+	s.Config.ManagerID = account.LoginCustomerID
+	return account, nil
+}
 
-	// Fazer a requisição HTTP
-	client := &http.Client{}
-	resp, err := client.Do(req)
+// GetCampaignInsights obtém insights detalhados de uma campanha específica da conta informada, somados no período de opts
+func (s *GoogleAdsService) GetCampaignInsights(clientID, clientSecret, refreshToken, accountID, campaignID string, opts GoogleAdsReportOptions) (*models.GoogleAdsData, error) {
+	opts.Segment = ""
+	series, err := s.campaignInsights(clientID, clientSecret, refreshToken, accountID, campaignID, opts)
 	if err != nil {
-		fmt.Printf("Erro na chamada à API real: %v\n", err)
-		return nil, fmt.Errorf("erro na chamada à API do Google Ads: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	// Verificar o status da resposta
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		fmt.Printf("API retornou status %d: %s\n", resp.StatusCode, string(body))
-		fmt.Printf("Headers da resposta: %v\n", resp.Header)
-		return nil, fmt.Errorf("API do Google Ads retornou erro: status %d - %s", resp.StatusCode, string(body))
-	}
-
-	// Processar a resposta
-	var responseData map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-		return nil, fmt.Errorf("erro ao decodificar resposta: %w", err)
-	}
-
-	// Processar e extrair dados reais da resposta da API
-	data := &models.GoogleAdsData{
-		ID:   campaignID,
-		Nome: "Campanha Real - " + campaignID,
-	}
-
-	// Extrair as métricas da resposta - isso teria que ser implementado adequadamente
-	// para processar os dados reais da API do Google Ads
-	fmt.Printf("Processando dados reais da API do Google Ads para a campanha %s\n", campaignID)
-
-	// Exemplo de como você extrairia dados reais:
-	// Por enquanto, estamos apenas inicializando com zeros
-	// Em uma implementação real, você extrairia esses valores da resposta
-	data.Impressions = 0
-	data.Clicks = 0
-	data.CTR = 0
-	data.CPC = 0
-	data.Conversoes = 0
-	data.TaxaConversao = 0
-	data.CustoConversao = 0
-	data.InvestimentoTotal = 0
-
-	// Exemplo de como extrair campos da resposta (isto é um exemplo simplificado)
-	if results, ok := responseData["results"]; ok {
-		if resultsArray, ok := results.([]interface{}); ok && len(resultsArray) > 0 {
-			fmt.Printf("Encontrados %d resultados na resposta\n", len(resultsArray))
-		}
+	if len(series) == 0 {
+		return nil, fmt.Errorf("campanha %s não encontrada na conta %s", campaignID, accountID)
 	}
+	return &series[0], nil
+}
 
-	return data, nil
+// GetCampaignInsightsSeries obtém os insights de uma campanha como uma série temporal, um ponto por período de opts.Segment
+func (s *GoogleAdsService) GetCampaignInsightsSeries(clientID, clientSecret, refreshToken, accountID, campaignID string, opts GoogleAdsReportOptions) ([]models.GoogleAdsData, error) {
+	return s.campaignInsights(clientID, clientSecret, refreshToken, accountID, campaignID, opts)
 }
 
-// GetAccountInsights obtém insights da conta de anúncios
-func (s *GoogleAdsService) GetAccountInsights(clientID, clientSecret, refreshToken, accountID string) (*models.GoogleAdsData, error) {
+// campaignInsights consulta as métricas da campanha e as soma por período da segmentação
+func (s *GoogleAdsService) campaignInsights(clientID, clientSecret, refreshToken, accountID, campaignID string, opts GoogleAdsReportOptions) ([]models.GoogleAdsData, error) {
 	if clientID == "" || clientSecret == "" || refreshToken == "" {
 		return nil, errors.New("credenciais incompletas fornecidas")
 	}
@@ -335,6 +291,20 @@ func (s *GoogleAdsService) GetAccountInsights(clientID, clientSecret, refreshTok
 		return nil, errors.New("ID da conta não fornecido")
 	}
 
+	if campaignID == "" {
+		return nil, errors.New("ID da campanha não fornecido")
+	}
+
+	query, err := opts.apply(newGAQLQuery("campaign").
+		Select("campaign.id", "campaign.name").
+		Select(googleAdsMetricsFields...).
+		Select(googleAdsImpressionShareFields...).
+		WhereID("campaign.id", campaignID)).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+
 	// Configurar o serviço com as credenciais fornecidas
 	s.Config.ClientID = clientID
 	s.Config.ClientSecret = clientSecret
@@ -345,129 +315,50 @@ func (s *GoogleAdsService) GetAccountInsights(clientID, clientSecret, refreshTok
 		return nil, fmt.Errorf("erro ao atualizar token de acesso: %w", err)
 	}
 
-	// Construir a URL da API
-	// Usando a versão v19 (mais estável e bem documentada)
-	apiURL := fmt.Sprintf("https://googleads.googleapis.com/v19/customers/%s:search", accountID)
-
-	// Construir o payload da requisição (JSON)
-	payload := map[string]interface{}{
-		"query": "SELECT customer.id, customer.descriptive_name, metrics.clicks, metrics.impressions, metrics.ctr, metrics.average_cpc, metrics.cost_micros, metrics.conversions, metrics.cost_per_conversion FROM customer WHERE segments.date DURING LAST_30_DAYS",
+	rows, err := s.search(token.AccessToken, accountID, query)
+	if err != nil {
+		return nil, err
 	}
 
-	// Converter o payload para JSON
-	payloadBytes, err := json.Marshal(payload)
+	conversions, err := s.conversionsByAction(token.AccessToken, s.Config.ManagerID, accountID, campaignConversionsQuery(campaignID), opts, opts.conversionKey)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao serializar payload: %w", err)
+		return nil, err
 	}
 
-	// Criar a requisição HTTP
-	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(payloadBytes))
-	if err != nil {
-		return nil, fmt.Errorf("erro ao criar requisição: %w", err)
+	// Somar as linhas da campanha por período (um único total sem segmentação)
+	nome := ""
+	periods := newGoogleAdsPeriods()
+	for _, row := range rows {
+		periods.add(opts.period(row), conversions.metrics(row, opts.conversionKey))
+		nome = row.Campaign.Name
 	}
 
-	// Adicionar headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
-	req.Header.Set("developer-token", s.Config.DeveloperToken)
+	return periods.data(campaignID, nome), nil
+}
 
-	// Enviar a requisição
-	client := &http.Client{}
-	resp, err := client.Do(req)
+// GetAccountInsights obtém insights da conta de anúncios, somados no período de opts
+func (s *GoogleAdsService) GetAccountInsights(clientID, clientSecret, refreshToken, accountID string, opts GoogleAdsReportOptions) (*models.GoogleAdsData, error) {
+	opts.Segment = ""
+	series, err := s.accountInsights(clientID, clientSecret, refreshToken, accountID, opts)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao enviar requisição: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	// Verificar o status da resposta
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API do Google Ads retornou erro: status %d - %s", resp.StatusCode, string(body))
-	}
-
-	// Processar a resposta
-	var responseData map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-		return nil, fmt.Errorf("erro ao decodificar resposta: %w", err)
-	}
-
-	// Extrair os dados relevantes da resposta
-	// Nota: Esta é uma implementação simplificada, a estrutura real da resposta pode ser diferente
-	var impressions int = 0
-	var clicks int = 0
-	var conversoes int = 0
-	var custoMicros int64 = 0
-	var nome string = "Conta " + accountID
-
-	// Processar os resultados
-	if results, ok := responseData["results"].([]interface{}); ok && len(results) > 0 {
-		for _, result := range results {
-			resultMap, ok := result.(map[string]interface{})
-			if !ok {
-				continue
-			}
-
-			// Extrair métricas
-			if metrics, ok := resultMap["metrics"].(map[string]interface{}); ok {
-				if imp, ok := metrics["impressions"].(float64); ok {
-					impressions += int(imp)
-				}
-				if clk, ok := metrics["clicks"].(float64); ok {
-					clicks += int(clk)
-				}
-				if conv, ok := metrics["conversions"].(float64); ok {
-					conversoes += int(conv)
-				}
-				if cost, ok := metrics["cost_micros"].(float64); ok {
-					custoMicros += int64(cost)
-				}
-			}
-
-			// Extrair nome da conta
-			if customer, ok := resultMap["customer"].(map[string]interface{}); ok {
-				if descriptiveName, ok := customer["descriptive_name"].(string); ok && descriptiveName != "" {
-					nome = descriptiveName
-				}
-			}
-		}
+	if len(series) == 0 {
+		// Conta sem linhas no período: retornar as métricas zeradas
+		data := newGoogleAdsData(normalizeGoogleCustomerID(accountID), "Conta "+accountID, googleAdsMetrics{})
+		return &data, nil
 	}
+	return &series[0], nil
+}
 
-	// Calcular métricas
-	custo := float64(custoMicros) / 1000000.0 // Converter micros para reais
-	ctr := 0.0
-	if impressions > 0 {
-		ctr = float64(clicks) / float64(impressions) * 100.0
-	}
-	cpc := 0.0
-	if clicks > 0 {
-		cpc = custo / float64(clicks)
-	}
-	taxaConversao := 0.0
-	if clicks > 0 {
-		taxaConversao = float64(conversoes) / float64(clicks) * 100.0
-	}
-	custoConversao := 0.0
-	if conversoes > 0 {
-		custoConversao = custo / float64(conversoes)
-	}
-
-	// Criar e retornar o objeto de dados
-	return &models.GoogleAdsData{
-		ID:                accountID,
-		Nome:              nome,
-		CTR:               roundFloat(ctr, 2),
-		CPC:               roundFloat(cpc, 2),
-		Conversoes:        conversoes,
-		TaxaConversao:     roundFloat(taxaConversao, 2),
-		CustoConversao:    roundFloat(custoConversao, 2),
-		InvestimentoTotal: roundFloat(custo, 2),
-		Impressions:       impressions,
-		Clicks:            clicks,
-	}, nil
+// GetAccountInsightsSeries obtém os insights da conta como uma série temporal, um ponto por período de opts.Segment
+func (s *GoogleAdsService) GetAccountInsightsSeries(clientID, clientSecret, refreshToken, accountID string, opts GoogleAdsReportOptions) ([]models.GoogleAdsData, error) {
+	return s.accountInsights(clientID, clientSecret, refreshToken, accountID, opts)
 }
 
-// ListCampaigns lista as campanhas disponíveis para a conta
-func (s *GoogleAdsService) ListCampaigns(clientID, clientSecret, refreshToken, accountID string) ([]models.GoogleAdsData, error) {
+// accountInsights consulta as métricas da conta e as soma por período da segmentação
+func (s *GoogleAdsService) accountInsights(clientID, clientSecret, refreshToken, accountID string, opts GoogleAdsReportOptions) ([]models.GoogleAdsData, error) {
 	if clientID == "" || clientSecret == "" || refreshToken == "" {
 		return nil, errors.New("credenciais incompletas fornecidas")
 	}
@@ -476,6 +367,15 @@ func (s *GoogleAdsService) ListCampaigns(clientID, clientSecret, refreshToken, a
 		return nil, errors.New("ID da conta não fornecido")
 	}
 
+	query, err := opts.apply(newGAQLQuery("customer").
+		Select("customer.id", "customer.descriptive_name").
+		Select(googleAdsMetricsFields...).
+		Select(googleAdsImpressionShareFields...)).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+
 	// Configurar o serviço com as credenciais fornecidas
 	s.Config.ClientID = clientID
 	s.Config.ClientSecret = clientSecret
@@ -486,229 +386,78 @@ func (s *GoogleAdsService) ListCampaigns(clientID, clientSecret, refreshToken, a
 		return nil, fmt.Errorf("erro ao atualizar token de acesso: %w", err)
 	}
 
-	// Construir a URL da API
-	// Usando a versão v19 (mais estável e bem documentada)
-	apiURL := fmt.Sprintf("https://googleads.googleapis.com/v19/customers/%s:search", accountID)
-
-	// Construir o payload da requisição (JSON)
-	payload := map[string]interface{}{
-		"query": "SELECT campaign.id, campaign.name, metrics.clicks, metrics.impressions, metrics.ctr, metrics.average_cpc, metrics.cost_micros, metrics.conversions, metrics.cost_per_conversion FROM campaign WHERE segments.date DURING LAST_30_DAYS",
-	}
-
-	// Converter o payload para JSON
-	payloadBytes, err := json.Marshal(payload)
+	rows, err := s.search(token.AccessToken, accountID, query)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao serializar payload: %w", err)
+		return nil, err
 	}
 
-	// Criar a requisição HTTP
-	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(payloadBytes))
-	if err != nil {
-		return nil, fmt.Errorf("erro ao criar requisição: %w", err)
-	}
-
-	// Adicionar headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
-	req.Header.Set("developer-token", s.Config.DeveloperToken)
-
-	// Enviar a requisição
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	conversions, err := s.conversionsByAction(token.AccessToken, s.Config.ManagerID, accountID, accountConversionsQuery(), opts, opts.conversionKey)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao enviar requisição: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
-
-	// Verificar o status da resposta
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API do Google Ads retornou erro: status %d - %s", resp.StatusCode, string(body))
-	}
-
-	// Processar a resposta
-	var responseData map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-		return nil, fmt.Errorf("erro ao decodificar resposta: %w", err)
-	}
-
-	// Lista para armazenar as campanhas
-	var campaigns []models.GoogleAdsData
-
-	// Processar os resultados
-	if results, ok := responseData["results"].([]interface{}); ok {
-		for _, result := range results {
-			resultMap, ok := result.(map[string]interface{})
-			if !ok {
-				continue
-			}
-
-			// Extrair dados da campanha
-			var campaignID string = ""
-			var campaignName string = ""
-			var impressions int = 0
-			var clicks int = 0
-			var conversoes int = 0
-			var custoMicros int64 = 0
-
-			// Extrair ID e nome da campanha
-			if campaign, ok := resultMap["campaign"].(map[string]interface{}); ok {
-				if id, ok := campaign["id"].(string); ok {
-					campaignID = id
-				}
-				if name, ok := campaign["name"].(string); ok {
-					campaignName = name
-				}
-			}
 
-			// Extrair métricas
-			if metrics, ok := resultMap["metrics"].(map[string]interface{}); ok {
-				if imp, ok := metrics["impressions"].(float64); ok {
-					impressions = int(imp)
-				}
-				if clk, ok := metrics["clicks"].(float64); ok {
-					clicks = int(clk)
-				}
-				if conv, ok := metrics["conversions"].(float64); ok {
-					conversoes = int(conv)
-				}
-				if cost, ok := metrics["cost_micros"].(float64); ok {
-					custoMicros = int64(cost)
-				}
-			}
-
-			// Pular campanhas sem ID
-			if campaignID == "" {
-				continue
-			}
-
-			// Calcular métricas
-			custo := float64(custoMicros) / 1000000.0 // Converter micros para reais
-			ctr := 0.0
-			if impressions > 0 {
-				ctr = float64(clicks) / float64(impressions) * 100.0
-			}
-			cpc := 0.0
-			if clicks > 0 {
-				cpc = custo / float64(clicks)
-			}
-			taxaConversao := 0.0
-			if clicks > 0 {
-				taxaConversao = float64(conversoes) / float64(clicks) * 100.0
-			}
-			custoConversao := 0.0
-			if conversoes > 0 {
-				custoConversao = custo / float64(conversoes)
-			}
-
-			// Adicionar campanha à lista
-			campaigns = append(campaigns, models.GoogleAdsData{
-				ID:                campaignID,
-				Nome:              campaignName,
-				CTR:               roundFloat(ctr, 2),
-				CPC:               roundFloat(cpc, 2),
-				Conversoes:        conversoes,
-				TaxaConversao:     roundFloat(taxaConversao, 2),
-				CustoConversao:    roundFloat(custoConversao, 2),
-				InvestimentoTotal: roundFloat(custo, 2),
-				Impressions:       impressions,
-				Clicks:            clicks,
-			})
+	// Somar as linhas da conta por período e usar o nome descritivo, se houver
+	nome := "Conta " + accountID
+	periods := newGoogleAdsPeriods()
+	for _, row := range rows {
+		periods.add(opts.period(row), conversions.metrics(row, opts.conversionKey))
+		if row.Customer.DescriptiveName != "" {
+			nome = row.Customer.DescriptiveName
 		}
 	}
 
-	return campaigns, nil
+	return periods.data(normalizeGoogleCustomerID(accountID), nome), nil
 }
 
-
-
-
-
-
-
-
-
-// GetConsolidatedCampaignData obtém dados consolidados de todas as campanhas de todas as contas
-func (s *GoogleAdsService) GetConsolidatedCampaignData(clientID, clientSecret, refreshToken string) ([]*models.GoogleAdsData, error) {
+// ListCampaigns lista as campanhas disponíveis para a conta com as métricas do período de opts;
+// com opts.Segment, cada campanha tem uma linha por período
+func (s *GoogleAdsService) ListCampaigns(clientID, clientSecret, refreshToken, accountID string, opts GoogleAdsReportOptions) ([]models.GoogleAdsData, error) {
 	if clientID == "" || clientSecret == "" || refreshToken == "" {
 		return nil, errors.New("credenciais incompletas fornecidas")
 	}
 
-	fmt.Printf("Iniciando busca de dados consolidados do Google Ads\n")
+	if accountID == "" {
+		return nil, errors.New("ID da conta não fornecido")
+	}
+
+	query, err := googleAdsCampaignsQuery(opts)
+	if err != nil {
+		return nil, err
+	}
 
 	// Configurar o serviço com as credenciais fornecidas
 	s.Config.ClientID = clientID
 	s.Config.ClientSecret = clientSecret
 
 	// Obter token de acesso atualizado
-	log.Printf("Tentando atualizar token de acesso com refresh_token fornecido...\n")
-	tokenResponse, err := s.RefreshAccessToken(refreshToken)
+	token, err := s.RefreshAccessToken(refreshToken)
 	if err != nil {
-		log.Printf("Erro ao atualizar token de acesso: %v\n", err)
 		return nil, fmt.Errorf("erro ao atualizar token de acesso: %w", err)
 	}
-	log.Printf("Token de acesso atualizado com sucesso!\n")
 
-	fmt.Printf("Token de acesso obtido com sucesso. Tentando acessar a API real...\n")
-
-	// Em uma implementação real completa, faríamos:
-	// 1. Listar todas as contas de anúncios disponíveis
-	// 2. Para cada conta, listar todas as campanhas
-	// 3. Para cada campanha, obter os insights detalhados
-	// 4. Consolidar todos os dados em uma única lista
-
-	// Tentar fazer uma chamada real à API para verificar se o token funciona
-	// e se conseguimos obter algum dado real
-	log.Printf("Testando conexão com a API do Google Ads...\n")
-	testResult, err := s.TestGoogleAdsConnection(refreshToken, clientID, clientSecret)
+	conversions, err := s.conversionsByAction(token.AccessToken, s.Config.ManagerID, accountID, campaignConversionsQuery(""), opts, opts.conversionKey)
 	if err != nil {
-		log.Printf("Erro ao testar conexão com a API: %v\n", err)
-		return nil, fmt.Errorf("erro ao testar conexão com a API: %w", err)
-	}
-
-	fmt.Printf("Conexão com a API testada com sucesso: %v\n", testResult)
-
-	// Tentar obter dados reais da API
-	// Como a integração completa com a API do Google Ads é complexa,
-	// vamos realizar chamadas básicas para demonstrar a conexão funcional
-
-	// Tentar obter dados básicos da conta
-	var consolidated []*models.GoogleAdsData
-
-	// Como não temos mais código mockado, retornamos um array vazio ou um exemplo básico
-	// mostrando que a conexão foi bem-sucedida
-
-	// Adiciona um objeto representando o sucesso da autenticação
-	dummyData := &models.GoogleAdsData{
-		ID:                "connection_test",
-		Nome:              "Teste de Conexão",
-		CTR:               0,
-		CPC:               0,
-		Conversoes:        0,
-		TaxaConversao:     0,
-		CustoConversao:    0,
-		InvestimentoTotal: 0,
-		Impressions:       0,
-		Clicks:            0,
-	}
-
-	consolidated = append(consolidated, dummyData)
-
-	fmt.Printf("Autenticação realizada com sucesso. Dados de teste retornados.\n")
-	
-	// Adicionar informações do token
-	fmt.Printf("Token tipo: %s, expira em: %d segundos.\n", tokenResponse.TokenType, tokenResponse.ExpiresIn)
-	
-	// Para implementar a integração completa, é necessário implementar as chamadas à API Google Ads
-	fmt.Printf("Total de %d itens consolidados retornados (dados de conexão)\n", len(consolidated))
-	return consolidated, nil
-}
-
-
-
+		return nil, err
+	}
 
+	// Lista para armazenar as campanhas, montada à medida que as linhas chegam pelo searchStream
+	campaigns := []models.GoogleAdsData{}
 
+	err = s.streamAs(token.AccessToken, s.Config.ManagerID, accountID, query, func(row googleAdsRow) error {
+		// Pular campanhas sem ID
+		if row.Campaign.ID != 0 {
+			campaign := newGoogleAdsData(strconv.FormatInt(int64(row.Campaign.ID), 10), row.Campaign.Name, conversions.metrics(row, opts.conversionKey))
+			campaign.Periodo = opts.period(row)
+			campaigns = append(campaigns, campaign)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
+	return campaigns, nil
+}
 
 // extractErrorInfoFromGoogleAds extrai informações detalhadas de um erro da API do Google Ads
 func extractErrorInfoFromGoogleAds(err error) *models.ErrorInfo {
@@ -790,135 +539,45 @@ func (s *GoogleAdsService) GetAccountBasicInfo(clientID, clientSecret, refreshTo
 	accessToken := tokenResponse.AccessToken
 
 	// Etapa 1: Listar contas acessíveis
-	apiURL := "https://googleads.googleapis.com/v19/customers:listAccessibleCustomers"
-
-	// Criar a requisição GET (método correto para listAccessibleCustomers)
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao criar requisição para listar contas: %v", err)
-	}
-
-	// Obter o token de desenvolvedor
-	developerToken := s.Config.DeveloperToken
-	if developerToken == "" {
-		// Se não estiver na configuração, tentar da variável de ambiente
-		developerToken = os.Getenv("GOOGLE_ADS_DEVELOPER_TOKEN")
-		if developerToken == "" {
-			// Tentar o nome alternativo da variável por compatibilidade
-			developerToken = os.Getenv("GOOGLE_DEVELOPER_TOKEN")
-			if developerToken == "" {
-				return nil, fmt.Errorf("developer token não encontrado. Configure a variável de ambiente GOOGLE_ADS_DEVELOPER_TOKEN")
-			}
-		}
-		// Salvar na configuração para uso futuro
-		s.Config.DeveloperToken = developerToken
-	}
-
-	// Configurar headers necessários conforme documentação
-	req.Header.Set("Authorization", "Bearer "+accessToken)
-	req.Header.Set("developer-token", developerToken)
-	// O login-customer-id é opcional na primeira chamada, mas vamos incluí-lo se tivermos o accountID
-	if accountID != "" {
-		req.Header.Set("login-customer-id", accountID)
-	}
-	// Mostra apenas os primeiros 10 caracteres do token para debug
-	tokenPreview := developerToken
-	if len(developerToken) > 10 {
-		tokenPreview = developerToken[:10] + "..."
-	}
-	log.Printf("Developer Token: %s", tokenPreview)
-
-	// Executar a requisição
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao executar requisição para listar contas: %v", err)
-	}
-	defer resp.Body.Close()
-
-	// Ler o corpo da resposta
-	body, err := io.ReadAll(resp.Body)
+	customerIDs, err := s.listAccessibleCustomers(accessToken)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao ler resposta da listagem de contas: %v", err)
+		return nil, err
 	}
 
-	// Verificar status code
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("erro ao listar contas (status %d): %s", resp.StatusCode, string(body))
-	}
-
-	// Processar a resposta para obter a lista de contas acessíveis
-	var listResponse map[string]interface{}
-	if err := json.Unmarshal(body, &listResponse); err != nil {
-		return nil, fmt.Errorf("erro ao decodificar resposta da listagem de contas: %v", err)
-	}
-
-	// Verificar se o ID da conta solicitada está entre as contas acessíveis
-	resourceNames, ok := listResponse["resourceNames"].([]interface{})
-	if !ok {
-		return nil, fmt.Errorf("formato inesperado na resposta da listagem de contas")
+	// Verificar se há contas acessíveis e se a conta solicitada está entre elas
+	log.Printf("Contas acessíveis: %+v", customerIDs)
+	if len(customerIDs) == 0 {
+		return nil, fmt.Errorf("não foram encontradas contas acessíveis para este token")
 	}
 
-	// Verificar se há contas acessíveis e se a conta solicitada está entre elas
-	log.Printf("Contas acessíveis: %+v", resourceNames)
-	accountResourceName := "customers/" + accountID
 	accountFound := false
-
-	for _, name := range resourceNames {
-		resourceName, ok := name.(string)
-		if ok && resourceName == accountResourceName {
+	for _, customerID := range customerIDs {
+		if customerID == accountID {
 			accountFound = true
 			break
 		}
 	}
 
-	if !accountFound && len(resourceNames) > 0 {
-		// Se a conta solicitada não foi encontrada, mas temos outras contas disponíveis,
-		// log para debug
-		log.Printf("Conta %s não encontrada entre as contas acessíveis.", accountID)
-	}
-
-	// Verificar se encontramos alguma conta acessível
-	if len(resourceNames) == 0 {
-		return nil, fmt.Errorf("não foram encontradas contas acessíveis para este token")
-	}
-
-	// Usar a primeira conta disponível se a conta solicitada não foi encontrada
+	// Contas abaixo da conta gerenciadora não aparecem entre as acessíveis, mas são acessadas pelo login-customer-id;
+	// sem conta gerenciadora, usar a primeira conta disponível se a conta solicitada não foi encontrada
 	searchAccountID := accountID
-	if !accountFound && len(resourceNames) > 0 {
-		// Extrair o ID da primeira conta disponível
-		firstAccount, ok := resourceNames[0].(string)
-		if ok {
-			parts := strings.Split(firstAccount, "/")
-			if len(parts) > 1 {
-				searchAccountID = parts[len(parts)-1]
-				log.Printf("Usando a primeira conta disponível: %s", searchAccountID)
-			}
-		}
+	if !accountFound && normalizeGoogleCustomerID(s.Config.ManagerID) == "" {
+		log.Printf("Conta %s não encontrada entre as contas acessíveis.", accountID)
+		searchAccountID = customerIDs[0]
+		log.Printf("Usando a primeira conta disponível: %s", searchAccountID)
 	}
 
 	// Etapa 2: Obter informações da conta diretamente via GET
-	// Em vez de usar o endpoint search, vamos usar um endpoint direto para a conta
-	accountURL := fmt.Sprintf("https://googleads.googleapis.com/v19/customers/%s", searchAccountID)
-	log.Printf("Tentando acessar conta diretamente via: %s", accountURL)
-
-	// Criar requisição GET
-	accountReq, err := http.NewRequest("GET", accountURL, nil)
+	log.Printf("Tentando acessar conta diretamente: %s", searchAccountID)
+	accountReq, err := s.newRequest("GET", "/customers/"+searchAccountID, accessToken, nil)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao criar requisição para conta: %v", err)
 	}
 
-	// Configurar headers para a requisição da conta
-	accountReq.Header.Set("Authorization", "Bearer "+accessToken)
-	accountReq.Header.Set("developer-token", developerToken)
-	// O login-customer-id é crucial para a segunda chamada
-	accountReq.Header.Set("login-customer-id", searchAccountID)
-	log.Printf("Enviando requisição direta para a conta: %s com login-customer-id: %s", searchAccountID, searchAccountID)
-
 	// Executar a requisição para obter informações da conta
-	accountResp, err := client.Do(accountReq)
+	accountResp, err := s.do(accountReq)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao executar requisição da conta: %v", err)
+		return nil, fmt.Errorf("erro ao acessar conta: %w", err)
 	}
 	defer accountResp.Body.Close()
 
@@ -928,11 +587,6 @@ func (s *GoogleAdsService) GetAccountBasicInfo(clientID, clientSecret, refreshTo
 		return nil, fmt.Errorf("erro ao ler resposta da conta: %v", err)
 	}
 
-	// Verificar o status da resposta
-	if accountResp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("erro ao acessar conta (status %d): %s", accountResp.StatusCode, string(accountBody))
-	}
-
 	// Criar um objeto de informações da conta com base na resposta
 	log.Printf("Resposta da conta: %s", string(accountBody))
 
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"poc-integracoes-onm/models"
+
+	fb "github.com/huandu/facebook/v2"
+)
+
+// metaAccountStatuses traduz o account_status numérico da API para o nome do status
+var metaAccountStatuses = map[int]string{
+	1:   "ACTIVE",
+	2:   "DISABLED",
+	3:   "UNSETTLED",
+	7:   "PENDING_RISK_REVIEW",
+	8:   "PENDING_SETTLEMENT",
+	9:   "IN_GRACE_PERIOD",
+	100: "PENDING_CLOSURE",
+	101: "CLOSED",
+	201: "ANY_ACTIVE",
+	202: "ANY_CLOSED",
+}
+
+// metaZeroDecimalCurrencies são as moedas sem centavos, cujos valores a API já retorna em unidades monetárias
+var metaZeroDecimalCurrencies = map[string]bool{
+	"CLP": true, "COP": true, "CRC": true, "HUF": true, "IDR": true, "ISK": true,
+	"JPY": true, "KRW": true, "PYG": true, "TWD": true, "VND": true,
+}
+
+// GetAccountInfo obtém moeda, fuso horário, status, limite de gastos, valor gasto e empresa de uma conta de anúncios
+func (s *MetaAdsService) GetAccountInfo(token string, accountID string) (*models.MetaAdsAccountInfo, error) {
+	if token == "" {
+		return nil, errors.New("token não fornecido")
+	}
+
+	accountID = strings.TrimPrefix(accountID, "act_")
+	if accountID == "" {
+		return nil, errors.New("ID da conta não fornecido")
+	}
+
+	res, err := s.session(token).Get("/act_"+accountID, fb.Params{
+		"fields": "account_id,name,currency,timezone_name,account_status,spend_cap,amount_spent,business{id,name}",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter informações da conta: %w", err)
+	}
+
+	info := &models.MetaAdsAccountInfo{ID: accountID}
+	info.Nome, _ = res["name"].(string)
+	info.Moeda, _ = res["currency"].(string)
+	info.FusoHorario, _ = res["timezone_name"].(string)
+	info.StatusCodigo = int(insightFloat(res, "account_status"))
+	info.Status = metaAccountStatuses[info.StatusCodigo]
+	info.LimiteGastos = metaCurrencyAmount(res["spend_cap"], info.Moeda)
+	info.ValorGasto = metaCurrencyAmount(res["amount_spent"], info.Moeda)
+
+	if business, ok := res["business"].(map[string]interface{}); ok {
+		info.EmpresaID, _ = business["id"].(string)
+		info.EmpresaNome, _ = business["name"].(string)
+	}
+
+	return info, nil
+}
+
+// metaCurrencyAmount converte um valor retornado pela API na menor unidade da moeda (centavos) para unidades monetárias
+func metaCurrencyAmount(value interface{}, currency string) float64 {
+	var amount float64
+	switch v := value.(type) {
+	case string:
+		amount, _ = strconv.ParseFloat(v, 64)
+	case json.Number:
+		amount, _ = v.Float64()
+	case float64:
+		amount = v
+	}
+
+	if metaZeroDecimalCurrencies[strings.ToUpper(currency)] {
+		return amount
+	}
+	return roundFloat(amount/100, 2)
+}
+
+// metaMinorUnits converte um valor em unidades monetárias para a menor unidade da moeda (centavos) usada pela API;
+// moedas sem centavos são enviadas em unidades monetárias
+func metaMinorUnits(amount float64, currency string) int64 {
+	if metaZeroDecimalCurrencies[strings.ToUpper(currency)] {
+		return int64(math.Round(amount))
+	}
+	return int64(math.Round(amount * 100))
+}
+
+// accountCurrency obtém o código da moeda da conta de anúncios (ex: BRL, JPY)
+func (s *MetaAdsService) accountCurrency(session *fb.Session, accountID string) (string, error) {
+	accountID = strings.TrimPrefix(accountID, "act_")
+	if accountID == "" {
+		return "", errors.New("ID da conta não fornecido")
+	}
+
+	res, err := session.Get("/act_"+accountID, fb.Params{"fields": "currency"})
+	if err != nil {
+		return "", fmt.Errorf("erro ao obter a moeda da conta: %w", err)
+	}
+
+	currency, _ := res["currency"].(string)
+	return currency, nil
+}
@@ -4,10 +4,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"net/url"
+	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"poc-integracoes-onm/models"
 
@@ -27,15 +30,184 @@ type MetaAdsConfig struct {
 	State       string
 }
 
+// DefaultMetaActionTypes são os tipos de ação contados como venda quando o cliente não informa outros;
+// purchase já inclui as compras do pixel (offsite_conversion.fb_pixel_purchase)
+var DefaultMetaActionTypes = []string{"purchase"}
+
+// metaPurchaseActionTypes são os tipos da hierarquia de compras do Meta. purchase e omni_purchase já incluem
+// as compras do pixel, do site e do app, por isso, quando vários deles são informados, conta-se o maior valor
+// em vez da soma, para não contar a mesma compra mais de uma vez
+var metaPurchaseActionTypes = map[string]bool{
+	"purchase":                             true,
+	"omni_purchase":                        true,
+	"offsite_conversion.fb_pixel_purchase": true,
+	"onsite_web_purchase":                  true,
+	"onsite_web_app_purchase":              true,
+	"onsite_conversion.purchase":           true,
+	"app_custom_event.fb_mobile_purchase":  true,
+}
+
+// metaInsightsFields são os campos solicitados em todas as consultas de insights
+const metaInsightsFields = "account_currency,clicks,impressions,spend,reach,frequency,cpm,cpc,actions,action_values,purchase_roas,cost_per_action_type"
+
+// metaDatePresets são os valores de date_preset aceitos pela API de insights
+var metaDatePresets = map[string]bool{
+	"today": true, "yesterday": true, "this_month": true, "last_month": true,
+	"this_quarter": true, "maximum": true, "data_maximum": true, "last_3d": true,
+	"last_7d": true, "last_14d": true, "last_28d": true, "last_30d": true,
+	"last_90d": true, "last_week_mon_sun": true, "last_week_sun_sat": true,
+	"last_quarter": true, "last_year": true, "this_week_mon_today": true,
+	"this_week_sun_today": true, "this_year": true,
+}
+
+// metaTimeIncrements traduz os nomes aceitos pela nossa API para o time_increment do Meta
+var metaTimeIncrements = map[string]string{
+	"daily":   "1",
+	"weekly":  "7",
+	"monthly": "monthly",
+}
+
+// metaBreakdowns são os breakdowns de público e posicionamento aceitos nas consultas de insights
+var metaBreakdowns = map[string]bool{
+	"age": true, "gender": true, "publisher_platform": true, "platform_position": true,
+	"device_platform": true, "region": true, "country": true,
+}
+
+// metaActionBreakdowns são os action_breakdowns aceitos nas consultas de insights
+var metaActionBreakdowns = map[string]bool{
+	"action_type": true, "action_device": true, "action_destination": true,
+	"action_target_id": true, "action_reaction": true, "action_video_sound": true,
+	"action_video_type": true, "action_carousel_card_id": true, "action_carousel_card_name": true,
+	"action_canvas_component_name": true,
+}
+
+// defaultMetaDatePreset é o período usado quando nenhum intervalo é informado
+const defaultMetaDatePreset = "last_30d"
+
+// MetaInsightsOptions define os parâmetros opcionais das consultas de insights
+type MetaInsightsOptions struct {
+	ActionTypes   []string // Tipos de ação contados como venda; usa DefaultMetaActionTypes se vazio
+	DatePreset    string   // Qualquer date_preset do Meta (padrão last_30d)
+	Since         string   // Data inicial (AAAA-MM-DD); exige Until
+	Until         string   // Data final (AAAA-MM-DD); exige Since
+	TimeIncrement string   // daily, weekly, monthly ou número de dias (1 a 90) para séries temporais
+
+	Breakdowns       []string // Segmentação das linhas (age, gender, publisher_platform, ...)
+	ActionBreakdowns []string // Segmentação das ações dentro de cada linha (action_device, ...)
+}
+
+// HasBreakdowns indica se a consulta deve retornar linhas segmentadas
+func (o MetaInsightsOptions) HasBreakdowns() bool {
+	return len(o.Breakdowns) > 0 || len(o.ActionBreakdowns) > 0
+}
+
+// Validate verifica se o período e o incremento informados são aceitos pela API do Meta
+func (o MetaInsightsOptions) Validate() error {
+	if o.DatePreset != "" && !metaDatePresets[o.DatePreset] {
+		return fmt.Errorf("date_preset inválido: %s", o.DatePreset)
+	}
+
+	if (o.Since == "") != (o.Until == "") {
+		return errors.New("since e until devem ser informados juntos")
+	}
+
+	if o.Since != "" {
+		since, err := time.Parse("2006-01-02", o.Since)
+		if err != nil {
+			return fmt.Errorf("since inválido (use AAAA-MM-DD): %s", o.Since)
+		}
+		until, err := time.Parse("2006-01-02", o.Until)
+		if err != nil {
+			return fmt.Errorf("until inválido (use AAAA-MM-DD): %s", o.Until)
+		}
+		if until.Before(since) {
+			return errors.New("until deve ser igual ou posterior a since")
+		}
+	}
+
+	if o.TimeIncrement != "" && o.timeIncrement() == "" {
+		return fmt.Errorf("time_increment inválido: %s (use daily, weekly, monthly ou um número de 1 a 90)", o.TimeIncrement)
+	}
+
+	for _, breakdown := range o.Breakdowns {
+		if !metaBreakdowns[breakdown] {
+			return fmt.Errorf("breakdown inválido: %s (use age, gender, publisher_platform, platform_position, device_platform, region ou country)", breakdown)
+		}
+	}
+
+	for _, breakdown := range o.ActionBreakdowns {
+		if !metaActionBreakdowns[breakdown] {
+			return fmt.Errorf("action_breakdown inválido: %s", breakdown)
+		}
+	}
+
+	return nil
+}
+
+// timeIncrement retorna o time_increment no formato da API do Meta, ou vazio se inválido
+func (o MetaInsightsOptions) timeIncrement() string {
+	if increment, ok := metaTimeIncrements[o.TimeIncrement]; ok {
+		return increment
+	}
+	if days, err := strconv.Atoi(o.TimeIncrement); err == nil && days >= 1 && days <= 90 {
+		return o.TimeIncrement
+	}
+	return ""
+}
+
+// applyPeriod adiciona o período da consulta aos parâmetros; um intervalo since/until tem precedência sobre o date_preset
+func (o MetaInsightsOptions) applyPeriod(params fb.Params) {
+	if o.Since != "" && o.Until != "" {
+		timeRange, _ := json.Marshal(map[string]string{"since": o.Since, "until": o.Until})
+		params["time_range"] = string(timeRange)
+		return
+	}
+
+	preset := o.DatePreset
+	if preset == "" {
+		preset = defaultMetaDatePreset
+	}
+	params["date_preset"] = preset
+}
+
+// actionTypes retorna os tipos de ação que devem ser contados como venda
+func (o MetaInsightsOptions) actionTypes() []string {
+	if len(o.ActionTypes) == 0 {
+		return DefaultMetaActionTypes
+	}
+	return o.ActionTypes
+}
+
 // MetaAdsService implementa o serviço para integração com o Meta Ads
 type MetaAdsService struct {
 	// Configurações do serviço
 	Config MetaAdsConfig
+	// Paginação usada em todas as listagens da Graph API
+	Paginator MetaPaginator
+	// Controle de rate limit aplicado a todas as chamadas da Graph API
+	RateLimiter *MetaRateLimiter
+	// Número máximo de consultas simultâneas nos dados consolidados
+	Concurrency int
+}
+
+// DefaultMetaConcurrency é o número padrão de consultas simultâneas nos dados consolidados
+const DefaultMetaConcurrency = 5
+
+// metaConcurrency lê o número de consultas simultâneas da variável de ambiente META_CONCURRENCY
+func metaConcurrency() int {
+	if value, err := strconv.Atoi(os.Getenv("META_CONCURRENCY")); err == nil && value > 0 {
+		return value
+	}
+	return DefaultMetaConcurrency
 }
 
 // NewMetaAdsService cria uma nova instância do serviço Meta Ads
 func NewMetaAdsService() *MetaAdsService {
-	return &MetaAdsService{}
+	return &MetaAdsService{
+		Paginator:   NewMetaPaginator(),
+		RateLimiter: metaRateLimiter,
+		Concurrency: metaConcurrency(),
+	}
 }
 
 // NewMetaAdsServiceWithConfig cria uma nova instância do serviço Meta Ads com configurações específicas
@@ -47,9 +219,29 @@ func NewMetaAdsServiceWithConfig(appID, appSecret, redirectURI, state string) *M
 			RedirectURI: redirectURI,
 			State:       state,
 		},
+		Paginator:   NewMetaPaginator(),
+		RateLimiter: metaRateLimiter,
+		Concurrency: metaConcurrency(),
 	}
 }
 
+// session cria uma sessão da Graph API com o token informado, passando as chamadas pelo controle de rate limit.
+// Quando o App Secret está configurado, a sessão é criada a partir do aplicativo e toda chamada
+// leva o appsecret_proof, exigido pela opção "Require App Secret" do aplicativo.
+func (s *MetaAdsService) session(token string) *fb.Session {
+	app := fb.New(s.Config.AppID, s.Config.AppSecret)
+	app.EnableAppsecretProof = s.Config.AppSecret != ""
+
+	session := app.Session(token)
+	// O appsecret_proof é calculado na primeira chamada e guardado na sessão; calculá-lo aqui evita que
+	// consultas simultâneas com a mesma sessão (ex: GetConsolidatedCampaignData) escrevam nele ao mesmo tempo
+	session.AppsecretProof()
+	if s.RateLimiter != nil {
+		session.HttpClient = s.RateLimiter.Client()
+	}
+	return session
+}
+
 // GetAuthURL retorna a URL para autorização do usuário
 func (s *MetaAdsService) GetAuthURL() (string, error) {
 	if s.Config.AppID == "" || s.Config.RedirectURI == "" {
@@ -81,16 +273,40 @@ func (s *MetaAdsService) ExchangeCodeForToken(authorizationCode string) (*models
 		return nil, errors.New("código de autorização não fornecido")
 	}
 
-	tokenURL, err := url.Parse("https://graph.facebook.com/v15.0/oauth/access_token")
-	if err != nil {
-		return nil, fmt.Errorf("erro ao parsear URL do token: %w", err)
-	}
-
 	tokenParams := url.Values{}
 	tokenParams.Set("client_id", s.Config.AppID)
 	tokenParams.Set("redirect_uri", s.Config.RedirectURI)
 	tokenParams.Set("client_secret", s.Config.AppSecret)
 	tokenParams.Set("code", authorizationCode)
+
+	return requestMetaOAuthToken(tokenParams)
+}
+
+// ExchangeForLongLivedToken troca um token de usuário de curta duração por um token de longa duração (cerca de 60 dias)
+func (s *MetaAdsService) ExchangeForLongLivedToken(shortLivedToken string) (*models.OAuthTokenResponse, error) {
+	if s.Config.AppID == "" || s.Config.AppSecret == "" {
+		return nil, errors.New("configurações incompletas: AppID e AppSecret são obrigatórios")
+	}
+
+	if shortLivedToken == "" {
+		return nil, errors.New("token não fornecido")
+	}
+
+	tokenParams := url.Values{}
+	tokenParams.Set("grant_type", "fb_exchange_token")
+	tokenParams.Set("client_id", s.Config.AppID)
+	tokenParams.Set("client_secret", s.Config.AppSecret)
+	tokenParams.Set("fb_exchange_token", shortLivedToken)
+
+	return requestMetaOAuthToken(tokenParams)
+}
+
+// requestMetaOAuthToken chama o endpoint oauth/access_token da Graph API com os parâmetros informados
+func requestMetaOAuthToken(tokenParams url.Values) (*models.OAuthTokenResponse, error) {
+	tokenURL, err := url.Parse("https://graph.facebook.com/" + fb.Version + "/oauth/access_token")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao parsear URL do token: %w", err)
+	}
 	tokenURL.RawQuery = tokenParams.Encode()
 
 	// Realiza a requisição GET para obter o token
@@ -114,13 +330,13 @@ func (s *MetaAdsService) ExchangeCodeForToken(authorizationCode string) (*models
 }
 
 // GetMetricas obtém as métricas principais do Meta Ads usando o token fornecido
-func (s *MetaAdsService) GetMetricas(token string) (*models.MetaAdsData, error) {
+func (s *MetaAdsService) GetMetricas(token string, opts MetaInsightsOptions) (*models.MetaAdsData, error) {
 	if token == "" {
 		return nil, errors.New("token não fornecido")
 	}
 
 	// Criar uma sessão do Facebook com o token fornecido
-	session := fb.New("", "").Session(token)
+	session := s.session(token)
 
 	// Verificar se o token é válido fazendo uma chamada simples
 	_, err := session.Get("/me", fb.Params{})
@@ -130,7 +346,7 @@ func (s *MetaAdsService) GetMetricas(token string) (*models.MetaAdsData, error)
 
 	// Obter as contas de anúncios disponíveis para o token
 	params := fb.Params{
-		"fields": "account_id,name",
+		"fields": "account_id,name,currency",
 		"limit":  "1", // Apenas a primeira conta para simplificar
 	}
 	res, err := session.Get("/me/adaccounts", params)
@@ -152,21 +368,73 @@ func (s *MetaAdsService) GetMetricas(token string) (*models.MetaAdsData, error)
 	}
 
 	// Obter insights da conta de anúncios
-	return s.GetAccountInsights(token, accountID)
+	insights, err := s.GetAccountInsights(token, accountID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sem insights no período a moeda não vem nas linhas; usar a moeda da conta
+	if insights.Moeda == "" {
+		insights.Moeda, _ = account["currency"].(string)
+	}
+	return insights, nil
 }
 
 // GetCampaignInsights obtém insights detalhados de uma campanha específica
-func (s *MetaAdsService) GetCampaignInsights(token string, campaignID string) (*models.MetaAdsData, error) {
+func (s *MetaAdsService) GetCampaignInsights(token string, campaignID string, opts MetaInsightsOptions) (*models.MetaAdsData, error) {
+	rows, campanhaNome, err := s.campaignInsights(token, campaignID, opts, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return processInsightsData(rows, campaignID, campanhaNome, opts.actionTypes())
+}
+
+// GetCampaignInsightsSeries obtém a série temporal de insights de uma campanha, um ponto por time_increment
+func (s *MetaAdsService) GetCampaignInsightsSeries(token string, campaignID string, opts MetaInsightsOptions) ([]models.MetaAdsData, error) {
+	if opts.TimeIncrement == "" {
+		opts.TimeIncrement = "daily"
+	}
+	opts.Breakdowns, opts.ActionBreakdowns = nil, nil
+
+	rows, campanhaNome, err := s.campaignInsights(token, campaignID, opts, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return processInsightsSeries(rows, campaignID, campanhaNome, opts.actionTypes()), nil
+}
+
+// GetCampaignInsightsSegments obtém os insights de uma campanha segmentados pelos breakdowns informados
+func (s *MetaAdsService) GetCampaignInsightsSegments(token string, campaignID string, opts MetaInsightsOptions) ([]models.MetaAdsSegment, error) {
+	if !opts.HasBreakdowns() {
+		return nil, errors.New("nenhum breakdown informado")
+	}
+
+	rows, campanhaNome, err := s.campaignInsights(token, campaignID, opts, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return processInsightsSegments(rows, campaignID, campanhaNome, opts), nil
+}
+
+// campaignInsights consulta o nome e os insights de uma campanha no período informado
+func (s *MetaAdsService) campaignInsights(token string, campaignID string, opts MetaInsightsOptions, detailed bool) ([]fb.Result, string, error) {
 	if token == "" {
-		return nil, errors.New("token não fornecido")
+		return nil, "", errors.New("token não fornecido")
 	}
 
 	if campaignID == "" {
-		return nil, errors.New("ID da campanha não fornecido")
+		return nil, "", errors.New("ID da campanha não fornecido")
+	}
+
+	if err := opts.Validate(); err != nil {
+		return nil, "", err
 	}
 
 	// Criar uma sessão do Facebook com o token fornecido
-	session := fb.New("", "").Session(token)
+	session := s.session(token)
 
 	// Obter informações da campanha
 	campaignParams := fb.Params{
@@ -174,27 +442,62 @@ func (s *MetaAdsService) GetCampaignInsights(token string, campaignID string) (*
 	}
 	campaignRes, err := session.Get("/"+campaignID, campaignParams)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao obter informações da campanha: %w", err)
+		return nil, "", fmt.Errorf("erro ao obter informações da campanha: %w", err)
 	}
 
 	campanhaNome, _ := campaignRes["name"].(string)
 
 	// Obter insights da campanha
-	params := fb.Params{
-		"fields":      "clicks,impressions,spend,actions,cost_per_action_type",
-		"date_preset": "last_30d",
-		"level":       "campaign",
-	}
-	res, err := session.Get("/"+campaignID+"/insights", params)
+	params := insightsParams("campaign", opts, detailed)
+	rows, err := s.Paginator.FetchAll(session, "/"+campaignID+"/insights", params)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao obter insights da campanha: %w", err)
+		return nil, "", fmt.Errorf("erro ao obter insights da campanha: %w", err)
 	}
 
-	return processInsightsData(res, campaignID, campanhaNome)
+	return rows, campanhaNome, nil
 }
 
 // GetAccountInsights obtém insights da conta de anúncios
-func (s *MetaAdsService) GetAccountInsights(token string, accountID string) (*models.MetaAdsData, error) {
+func (s *MetaAdsService) GetAccountInsights(token string, accountID string, opts MetaInsightsOptions) (*models.MetaAdsData, error) {
+	rows, err := s.accountInsights(token, accountID, opts, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return processInsightsData(rows, "", "Conta "+accountID, opts.actionTypes())
+}
+
+// GetAccountInsightsSeries obtém a série temporal de insights da conta de anúncios, um ponto por time_increment
+func (s *MetaAdsService) GetAccountInsightsSeries(token string, accountID string, opts MetaInsightsOptions) ([]models.MetaAdsData, error) {
+	if opts.TimeIncrement == "" {
+		opts.TimeIncrement = "daily"
+	}
+	opts.Breakdowns, opts.ActionBreakdowns = nil, nil
+
+	rows, err := s.accountInsights(token, accountID, opts, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return processInsightsSeries(rows, "", "Conta "+accountID, opts.actionTypes()), nil
+}
+
+// GetAccountInsightsSegments obtém os insights da conta de anúncios segmentados pelos breakdowns informados
+func (s *MetaAdsService) GetAccountInsightsSegments(token string, accountID string, opts MetaInsightsOptions) ([]models.MetaAdsSegment, error) {
+	if !opts.HasBreakdowns() {
+		return nil, errors.New("nenhum breakdown informado")
+	}
+
+	rows, err := s.accountInsights(token, accountID, opts, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return processInsightsSegments(rows, "", "Conta "+accountID, opts), nil
+}
+
+// accountInsights consulta os insights da conta de anúncios no período informado
+func (s *MetaAdsService) accountInsights(token string, accountID string, opts MetaInsightsOptions, detailed bool) ([]fb.Result, error) {
 	if token == "" {
 		return nil, errors.New("token não fornecido")
 	}
@@ -203,97 +506,444 @@ func (s *MetaAdsService) GetAccountInsights(token string, accountID string) (*mo
 		return nil, errors.New("ID da conta não fornecido")
 	}
 
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Criar uma sessão do Facebook com o token fornecido
-	session := fb.New("", "").Session(token)
+	session := s.session(token)
 
 	// Obter insights da conta de anúncios
-	params := fb.Params{
-		"fields":      "clicks,impressions,spend,actions,cost_per_action_type",
-		"date_preset": "last_30d",
-		"level":       "account",
-	}
-	res, err := session.Get("/act_"+accountID+"/insights", params)
+	params := insightsParams("account", opts, detailed)
+	rows, err := s.Paginator.FetchAll(session, "/act_"+accountID+"/insights", params)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao obter insights da conta: %w", err)
 	}
 
-	return processInsightsData(res, "", "Conta "+accountID)
+	return rows, nil
+}
+
+// GetCampaignAdSets lista os conjuntos de anúncios de uma campanha com status, orçamento, estratégia de lance e métricas
+func (s *MetaAdsService) GetCampaignAdSets(token string, campaignID string, opts MetaInsightsOptions) ([]models.MetaAdSetData, error) {
+	if token == "" {
+		return nil, errors.New("token não fornecido")
+	}
+
+	if campaignID == "" {
+		return nil, errors.New("ID da campanha não fornecido")
+	}
+
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	session := s.session(token)
+
+	// Listar os conjuntos de anúncios da campanha
+	rows, err := s.Paginator.FetchAll(session, "/"+campaignID+"/adsets", fb.Params{
+		"fields": "id,name,account_id,campaign_id,status,effective_status,daily_budget,lifetime_budget,bid_strategy",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter conjuntos de anúncios da campanha: %w", err)
+	}
+
+	// Orçamentos vêm na menor unidade da moeda da conta, que depende da moeda (ex: JPY não tem centavos)
+	currency := ""
+	if len(rows) > 0 {
+		accountID, _ := rows[0]["account_id"].(string)
+		if currency, err = s.accountCurrency(session, accountID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Obter os insights de todos os conjuntos em uma única consulta
+	insights, err := s.levelInsights(session, campaignID, "adset", opts)
+	if err != nil {
+		return nil, err
+	}
+
+	adSets := []models.MetaAdSetData{}
+	for _, adSetMap := range rows {
+		adSet := models.MetaAdSetData{}
+		adSet.ID, _ = adSetMap["id"].(string)
+		adSet.Nome, _ = adSetMap["name"].(string)
+		adSet.CampanhaID, _ = adSetMap["campaign_id"].(string)
+		adSet.Status, _ = adSetMap["status"].(string)
+		adSet.StatusEfetivo, _ = adSetMap["effective_status"].(string)
+		adSet.EstrategiaLance, _ = adSetMap["bid_strategy"].(string)
+		adSet.OrcamentoDiario = metaCurrencyAmount(adSetMap["daily_budget"], currency)
+		adSet.OrcamentoTotal = metaCurrencyAmount(adSetMap["lifetime_budget"], currency)
+
+		if insight, ok := insights[adSet.ID]; ok {
+			fillInsightMetrics(&adSet.MetaAdsData, insight, opts.actionTypes())
+		}
+		// Conjuntos sem insights no período não trazem account_currency
+		if adSet.Moeda == "" {
+			adSet.Moeda = currency
+		}
+
+		adSets = append(adSets, adSet)
+	}
+
+	return adSets, nil
+}
+
+// GetAds lista os anúncios de uma campanha ou de um conjunto de anúncios com status, criativo e métricas
+func (s *MetaAdsService) GetAds(token string, parentID string, opts MetaInsightsOptions) ([]models.MetaAdData, error) {
+	if token == "" {
+		return nil, errors.New("token não fornecido")
+	}
+
+	if parentID == "" {
+		return nil, errors.New("ID da campanha ou do conjunto de anúncios não fornecido")
+	}
+
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	session := s.session(token)
+
+	// Listar os anúncios da campanha ou do conjunto
+	rows, err := s.Paginator.FetchAll(session, "/"+parentID+"/ads", fb.Params{
+		"fields": "id,name,account_id,campaign_id,adset_id,status,effective_status,creative{id}",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter anúncios: %w", err)
+	}
+
+	// Moeda da conta, para os anúncios sem insights no período (que não trazem account_currency)
+	currency := ""
+	if len(rows) > 0 {
+		accountID, _ := rows[0]["account_id"].(string)
+		if currency, err = s.accountCurrency(session, accountID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Obter os insights de todos os anúncios em uma única consulta
+	insights, err := s.levelInsights(session, parentID, "ad", opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ads := []models.MetaAdData{}
+	for _, adMap := range rows {
+		ad := models.MetaAdData{}
+		ad.ID, _ = adMap["id"].(string)
+		ad.Nome, _ = adMap["name"].(string)
+		ad.CampanhaID, _ = adMap["campaign_id"].(string)
+		ad.ConjuntoID, _ = adMap["adset_id"].(string)
+		ad.Status, _ = adMap["status"].(string)
+		ad.StatusEfetivo, _ = adMap["effective_status"].(string)
+		if creative, ok := adMap["creative"].(map[string]interface{}); ok {
+			ad.CriativoID, _ = creative["id"].(string)
+		}
+
+		if insight, ok := insights[ad.ID]; ok {
+			fillInsightMetrics(&ad.MetaAdsData, insight, opts.actionTypes())
+		}
+		if ad.Moeda == "" {
+			ad.Moeda = currency
+		}
+
+		ads = append(ads, ad)
+	}
+
+	return ads, nil
+}
+
+// levelInsights consulta os insights agregados no nível informado (adset ou ad), indexados pelo ID do objeto
+func (s *MetaAdsService) levelInsights(session *fb.Session, parentID string, level string, opts MetaInsightsOptions) (map[string]fb.Result, error) {
+	params := insightsParams(level, opts, false)
+	params["fields"] = metaInsightsFields + "," + level + "_id"
+
+	rows, err := s.Paginator.FetchAll(session, "/"+parentID+"/insights", params)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter insights no nível %s: %w", level, err)
+	}
+
+	insights := make(map[string]fb.Result)
+	for _, insight := range rows {
+		if id, ok := insight[level+"_id"].(string); ok {
+			insights[id] = insight
+		}
+	}
+
+	return insights, nil
+}
+
+// insightsParams monta os parâmetros de uma consulta de insights no nível informado.
+// Consultas agregadas ignoram time_increment e breakdowns para que a API retorne uma única linha.
+func insightsParams(level string, opts MetaInsightsOptions, detailed bool) fb.Params {
+	params := fb.Params{
+		"fields": metaInsightsFields,
+		"level":  level,
+	}
+	opts.applyPeriod(params)
+
+	if detailed {
+		if increment := opts.timeIncrement(); increment != "" {
+			params["time_increment"] = increment
+		}
+		if len(opts.Breakdowns) > 0 {
+			params["breakdowns"] = strings.Join(opts.Breakdowns, ",")
+		}
+		if len(opts.ActionBreakdowns) > 0 {
+			params["action_breakdowns"] = strings.Join(opts.ActionBreakdowns, ",")
+		}
+	}
+
+	return params
 }
 
 // processInsightsData processa os dados de insights retornados pela API do Facebook
-func processInsightsData(res fb.Result, id string, nome string) (*models.MetaAdsData, error) {
+func processInsightsData(insights []fb.Result, id string, nome string, actionTypes []string) (*models.MetaAdsData, error) {
 	data := &models.MetaAdsData{
 		ID:   id,
 		Nome: nome,
 	}
 
 	// Verificar se temos dados de insights
-	insights, ok := res["data"].([]interface{})
-	if !ok || len(insights) == 0 {
+	if len(insights) == 0 {
 		return data, nil // Retornar dados vazios se não houver insights
 	}
 
-	// Obter o primeiro insight (normalmente é o único para o período especificado)
-	insight, ok := insights[0].(map[string]interface{})
-	if !ok {
-		return data, nil
+	// Usar o primeiro insight (normalmente é o único para o período especificado)
+	fillInsightMetrics(data, insights[0], actionTypes)
+	return data, nil
+}
+
+// processInsightsSeries processa todas as linhas de insights retornadas pela API, uma por período
+func processInsightsSeries(insights []fb.Result, id string, nome string, actionTypes []string) []models.MetaAdsData {
+	series := []models.MetaAdsData{}
+
+	for _, insight := range insights {
+		point := models.MetaAdsData{
+			ID:   id,
+			Nome: nome,
+		}
+		fillInsightMetrics(&point, insight, actionTypes)
+		series = append(series, point)
 	}
 
-	// Extrair métricas básicas
-	clicks, _ := insight["clicks"].(string)
-	impressions, _ := insight["impressions"].(string)
-	spend, _ := insight["spend"].(string)
+	return series
+}
 
-	// Converter para números
-	clicksNum, _ := strconv.ParseFloat(clicks, 64)
-	impressionsNum, _ := strconv.ParseFloat(impressions, 64)
-	spendNum, _ := strconv.ParseFloat(spend, 64)
+// processInsightsSegments processa as linhas de insights segmentadas pelos breakdowns da consulta
+func processInsightsSegments(insights []fb.Result, id string, nome string, opts MetaInsightsOptions) []models.MetaAdsSegment {
+	segments := []models.MetaAdsSegment{}
+
+	actionTypes := opts.actionTypes()
+	for _, insight := range insights {
+		segment := models.MetaAdsSegment{
+			Segmento: make(map[string]string),
+			MetaAdsData: models.MetaAdsData{
+				ID:   id,
+				Nome: nome,
+			},
+		}
+		for _, breakdown := range opts.Breakdowns {
+			segment.Segmento[breakdown], _ = insight[breakdown].(string)
+		}
+		fillInsightMetrics(&segment.MetaAdsData, insight, actionTypes)
 
-	// Calcular CTR
-	var ctr float64
-	if impressionsNum > 0 {
-		ctr = (clicksNum / impressionsNum) * 100
+		if len(opts.ActionBreakdowns) > 0 {
+			segment.Acoes = actionSegments(insight, actionTypes, opts.ActionBreakdowns)
+		}
+
+		segments = append(segments, segment)
 	}
 
-	// Extrair ações (vendas)
-	var vendas float64
-	actions, ok := insight["actions"].([]interface{})
-	if ok {
+	return segments
+}
+
+// actionSegments agrupa as ações de venda de uma linha pelos valores dos action_breakdowns
+func actionSegments(insight map[string]interface{}, actionTypes []string, actionBreakdowns []string) []models.MetaAdsActionSegment {
+	var segments []models.MetaAdsActionSegment
+	var vendas, valorVendas []metaActionTotals
+	index := make(map[string]int)
+
+	collect := func(field string, totals *[]metaActionTotals) {
+		actions, ok := insight[field].([]interface{})
+		if !ok {
+			return
+		}
+
 		for _, action := range actions {
 			actionMap, ok := action.(map[string]interface{})
 			if !ok {
 				continue
 			}
 
-			// Verificar se é uma ação de compra
 			actionType, _ := actionMap["action_type"].(string)
-			if actionType == "purchase" || actionType == "offsite_conversion.fb_pixel_purchase" {
-				actionValue, _ := actionMap["value"].(string)
-				if actionValue == "" {
-					// Se não tiver valor, apenas contar o número de ações
-					vendas++
-				} else {
-					// Se tiver valor, somar ao total
-					valueNum, _ := strconv.ParseFloat(actionValue, 64)
-					vendas += valueNum
-				}
+			if !containsString(actionTypes, actionType) {
+				continue
 			}
+
+			segmento := make(map[string]string)
+			var key string
+			for _, breakdown := range actionBreakdowns {
+				value, _ := actionMap[breakdown].(string)
+				segmento[breakdown] = value
+				key += breakdown + "=" + value + "|"
+			}
+
+			pos, ok := index[key]
+			if !ok {
+				pos = len(segments)
+				index[key] = pos
+				segments = append(segments, models.MetaAdsActionSegment{Segmento: segmento})
+				vendas = append(vendas, metaActionTotals{})
+				valorVendas = append(valorVendas, metaActionTotals{})
+			}
+
+			actionValue, _ := actionMap["value"].(string)
+			valueNum, _ := strconv.ParseFloat(actionValue, 64)
+			(*totals)[pos][actionType] += valueNum
 		}
 	}
 
+	collect("actions", &vendas)
+	collect("action_values", &valorVendas)
+
+	// Totalizar cada segmento sem contar a mesma compra em mais de um tipo de ação
+	for pos := range segments {
+		segments[pos].Vendas = vendas[pos].total()
+		segments[pos].ValorVendas = roundFloat(valorVendas[pos].total(), 2)
+	}
+
+	return segments
+}
+
+// fillInsightMetrics preenche as métricas a partir de uma linha de insights da API do Facebook
+func fillInsightMetrics(data *models.MetaAdsData, insight map[string]interface{}, actionTypes []string) {
+	// Extrair métricas básicas (a API retorna os números como strings)
+	clicksNum := insightFloat(insight, "clicks")
+	impressionsNum := insightFloat(insight, "impressions")
+	spendNum := insightFloat(insight, "spend")
+
+	// Calcular CTR
+	var ctr float64
+	if impressionsNum > 0 {
+		ctr = (clicksNum / impressionsNum) * 100
+	}
+
+	// Extrair ações (vendas) e o valor das vendas
+	vendas := sumActionValues(insight["actions"], actionTypes)
+	valorVendas := sumActionValues(insight["action_values"], actionTypes)
+
 	// Calcular CAC (Custo de Aquisição de Cliente)
 	var cac float64
 	if vendas > 0 {
 		cac = spendNum / vendas
 	}
 
+	// Calcular ROAS a partir do valor das vendas; se não houver e as vendas forem compras,
+	// usar o purchase_roas calculado pela própria API
+	var roas float64
+	if valorVendas > 0 && spendNum > 0 {
+		roas = valorVendas / spendNum
+	} else if containsString(actionTypes, "purchase") || containsString(actionTypes, "omni_purchase") {
+		roas = sumActionValues(insight["purchase_roas"], nil)
+	}
+
 	// Preencher os dados
+	data.DataInicio, _ = insight["date_start"].(string)
+	data.DataFim, _ = insight["date_stop"].(string)
 	data.CTR = ctr
 	data.CAC = cac
 	data.InvestimentoTotal = spendNum
 	data.NumeroVendas = int(vendas)
+	data.ValorVendas = roundFloat(valorVendas, 2)
+	data.ROAS = roundFloat(roas, 2)
+	data.CPM = roundFloat(insightFloat(insight, "cpm"), 2)
+	data.CPC = roundFloat(insightFloat(insight, "cpc"), 2)
+	data.Impressoes = int(impressionsNum)
+	data.Cliques = int(clicksNum)
+	data.Alcance = int(insightFloat(insight, "reach"))
+	data.Frequencia = roundFloat(insightFloat(insight, "frequency"), 2)
+	data.Moeda, _ = insight["account_currency"].(string)
+}
 
-	return data, nil
+// insightFloat converte um campo numérico de insights (enviado como string) para float
+func insightFloat(insight map[string]interface{}, field string) float64 {
+	switch value := insight[field].(type) {
+	case string:
+		number, _ := strconv.ParseFloat(value, 64)
+		return number
+	case float64:
+		return value
+	case json.Number:
+		number, _ := value.Float64()
+		return number
+	}
+	return 0
+}
+
+// sumActionValues soma os valores de uma lista de ações (actions, action_values, purchase_roas)
+// considerando apenas os tipos informados; se nenhum tipo for informado, soma apenas o primeiro item.
+// Os tipos da hierarquia de compras não são somados entre si (ver metaActionTotals).
+func sumActionValues(raw interface{}, actionTypes []string) float64 {
+	actions, ok := raw.([]interface{})
+	if !ok {
+		return 0
+	}
+
+	totals := metaActionTotals{}
+	for _, action := range actions {
+		actionMap, ok := action.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		actionType, _ := actionMap["action_type"].(string)
+		if len(actionTypes) > 0 && !containsString(actionTypes, actionType) {
+			continue
+		}
+
+		actionValue, _ := actionMap["value"].(string)
+		if actionValue == "" {
+			// Se não tiver valor, apenas contar o número de ações
+			totals[actionType]++
+		} else {
+			valueNum, _ := strconv.ParseFloat(actionValue, 64)
+			totals[actionType] += valueNum
+		}
+
+		if len(actionTypes) == 0 {
+			break
+		}
+	}
+
+	return totals.total()
+}
+
+// metaActionTotals acumula os valores das ações por tipo de ação
+type metaActionTotals map[string]float64
+
+// total soma os valores dos tipos de ação; dos tipos da hierarquia de compras conta-se apenas o maior valor,
+// já que purchase e omni_purchase incluem as compras dos demais tipos
+func (t metaActionTotals) total() float64 {
+	var total, purchases float64
+	for actionType, value := range t {
+		if metaPurchaseActionTypes[actionType] {
+			purchases = math.Max(purchases, value)
+			continue
+		}
+		total += value
+	}
+	return total + purchases
+}
+
+// containsString verifica se o valor está presente na lista
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
 }
 
 // FallbackToMockData retorna dados simulados quando a API real falha
@@ -309,6 +959,7 @@ func (s *MetaAdsService) FallbackToMockData(err error) (*models.MetaAdsResponse,
 		CAC:               15.75,
 		InvestimentoTotal: 1250.50,
 		NumeroVendas:      80,
+		Moeda:             "BRL",
 	}
 
 	// Retornar resposta com dados simulados e informações do erro
@@ -327,7 +978,8 @@ func extractErrorInfoFromMain(err error) *models.ErrorInfo {
 	}
 
 	// Verificar se é um erro do Facebook SDK
-	if fbErr, ok := err.(*fb.Error); ok {
+	var fbErr *fb.Error
+	if errors.As(err, &fbErr) {
 		errorInfo.Code = fbErr.Code
 		errorInfo.Type = fbErr.Type
 		errorInfo.Message = fbErr.Message
@@ -363,129 +1015,168 @@ func extractErrorInfoFromMain(err error) *models.ErrorInfo {
 	return errorInfo
 }
 
-// GetConsolidatedCampaignData fetches all campaigns across all accounts for the given token
-func (s *MetaAdsService) GetConsolidatedCampaignData(token string) ([]*models.MetaAdsData, error) {
+// GetConsolidatedCampaignData obtém os insights de todas as contas e campanhas do token, com até
+// s.Concurrency consultas simultâneas. Contas e campanhas que falharem são devolvidas na lista de
+// erros, sem interromper as demais nem aparecer com métricas zeradas.
+func (s *MetaAdsService) GetConsolidatedCampaignData(token string, opts MetaInsightsOptions) ([]*models.MetaAdsData, []models.MetaAdsItemError, error) {
 	if token == "" {
-		return nil, errors.New("token não fornecido")
+		return nil, nil, errors.New("token não fornecido")
+	}
+
+	if err := opts.Validate(); err != nil {
+		return nil, nil, err
 	}
 
-	session := fb.New("", "").Session(token)
+	session := s.session(token)
 
-	// Validate token
+	// Validar o token
 	_, err := session.Get("/me", fb.Params{})
 	if err != nil {
-		return nil, fmt.Errorf("token inválido ou não autorizado: %w", err)
+		return nil, nil, fmt.Errorf("token inválido ou não autorizado: %w", err)
 	}
 
-	// Fetch all ad accounts
+	// Obter todas as contas de anúncios
 	params := fb.Params{
-		"fields": "account_id,name",
-		"limit":  "1000", // large limit to get many accounts
+		"fields": "account_id,name,currency",
 	}
-	res, err := session.Get("/me/adaccounts", params)
+	accounts, err := s.Paginator.FetchAll(session, "/me/adaccounts", params)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao obter contas de anúncios: %w", err)
+		return nil, nil, fmt.Errorf("erro ao obter contas de anúncios: %w", err)
 	}
 
-	data, ok := res["data"].([]interface{})
-	if !ok || len(data) == 0 {
-		return nil, errors.New("nenhuma conta de anúncios encontrada para este token")
+	if len(accounts) == 0 {
+		return nil, nil, errors.New("nenhuma conta de anúncios encontrada para este token")
 	}
 
-	fmt.Printf("Encontradas %d contas de anúncios\n", len(data))
-
-	var consolidated []*models.MetaAdsData
+	fmt.Printf("Encontradas %d contas de anúncios\n", len(accounts))
 
-	for _, acc := range data {
-		accountMap, ok := acc.(map[string]interface{})
-		if !ok {
-			continue
-		}
-		accountID, _ := accountMap["account_id"].(string)
-		accountName, _ := accountMap["name"].(string)
+	// Primeira etapa: insights e campanhas de cada conta
+	type accountResult struct {
+		currency  string
+		insights  *models.MetaAdsData
+		campaigns []fb.Result
+		errs      []models.MetaAdsItemError
+	}
+	accountResults := make([]accountResult, len(accounts))
 
-		fmt.Printf("Processando conta: %s (%s)\n", accountName, accountID)
+	s.forEach(len(accounts), func(i int) {
+		accountID, _ := accounts[i]["account_id"].(string)
+		accountName, _ := accounts[i]["name"].(string)
+		result := &accountResults[i]
+		result.currency, _ = accounts[i]["currency"].(string)
 
-		// Adicionar dados da conta aos resultados consolidados
-		accountInsights, err := s.GetAccountInsights(token, accountID)
-		if err == nil && accountInsights != nil {
-			consolidated = append(consolidated, accountInsights)
-			fmt.Printf("Adicionados insights da conta %s\n", accountID)
+		insights, err := s.GetAccountInsights(token, accountID, opts)
+		if err != nil {
+			fmt.Printf("Erro ao obter insights da conta %s: %v\n", accountID, err)
+			result.errs = append(result.errs, metaItemError("conta", accountID, accountName, err))
+		} else {
+			result.insights = insights
 		}
 
-		// Fetch campaigns for this account - usando o prefixo act_ correto
-		campaignParams := fb.Params{
+		campaigns, err := s.Paginator.FetchAll(session, "/act_"+accountID+"/campaigns", fb.Params{
 			"fields": "id,name",
-			"limit":  "1000",
-		}
-		campaignsRes, err := session.Get(fmt.Sprintf("/act_%s/campaigns", accountID), campaignParams)
+		})
 		if err != nil {
 			fmt.Printf("Erro ao obter campanhas para a conta %s: %v\n", accountID, err)
-			continue // skip this account on error
+			result.errs = append(result.errs, metaItemError("conta", accountID, accountName, err))
+			return
 		}
 
-		campaignsData, ok := campaignsRes["data"].([]interface{})
-		if !ok {
-			fmt.Printf("Formato de dados inválido para campanhas da conta %s\n", accountID)
-			continue
+		fmt.Printf("Encontradas %d campanhas para a conta %s\n", len(campaigns), accountID)
+		result.campaigns = campaigns
+	})
+
+	// Segunda etapa: insights de todas as campanhas de todas as contas
+	type campaignResult struct {
+		account  int
+		id       string
+		nome     string
+		insights *models.MetaAdsData
+		err      error
+	}
+	var campaignResults []campaignResult
+	for i, result := range accountResults {
+		for _, campaign := range result.campaigns {
+			id, _ := campaign["id"].(string)
+			nome, _ := campaign["name"].(string)
+			campaignResults = append(campaignResults, campaignResult{account: i, id: id, nome: nome})
 		}
+	}
 
-		fmt.Printf("Encontradas %d campanhas para a conta %s\n", len(campaignsData), accountID)
-
-		if len(campaignsData) == 0 {
-			// Se não houver campanhas, adicionar pelo menos os dados da conta
-			if len(consolidated) == 0 { // Se ainda não adicionamos os insights da conta
-				accountData := &models.MetaAdsData{
-					ID:                accountID,
-					Nome:              accountName,
-					CTR:               0,
-					CAC:               0,
-					InvestimentoTotal: 0,
-					NumeroVendas:      0,
-				}
-				consolidated = append(consolidated, accountData)
-				fmt.Printf("Adicionados dados básicos da conta %s (sem campanhas)\n", accountID)
-			}
+	// As campanhas são consultadas em batches da Graph API, com vários batches simultâneos
+	campaignParams := insightsParams("campaign", opts, false)
+	batches := (len(campaignResults) + MetaBatchSize - 1) / MetaBatchSize
+	s.forEach(batches, func(b int) {
+		start := b * MetaBatchSize
+		end := start + MetaBatchSize
+		if end > len(campaignResults) {
+			end = len(campaignResults)
 		}
 
-		for _, camp := range campaignsData {
-			campMap, ok := camp.(map[string]interface{})
-			if !ok {
+		paths := make([]string, 0, end-start)
+		for _, result := range campaignResults[start:end] {
+			paths = append(paths, "/"+result.id+"/insights")
+		}
+
+		responses, errs := s.batchGet(session, paths, campaignParams)
+		for i := range paths {
+			result := &campaignResults[start+i]
+			if errs[i] != nil {
+				fmt.Printf("Erro ao obter insights para a campanha %s: %v\n", result.id, errs[i])
+				result.err = fmt.Errorf("erro ao obter insights da campanha: %w", errs[i])
 				continue
 			}
-			campaignID, _ := campMap["id"].(string)
-			campaignName, _ := campMap["name"].(string)
-
-			fmt.Printf("Processando campanha: %s (%s)\n", campaignName, campaignID)
-
-			// Fetch insights for this campaign
-			insights, err := s.GetCampaignInsights(token, campaignID)
-			if err != nil {
-				fmt.Printf("Erro ao obter insights para a campanha %s: %v\n", campaignID, err)
-				// Adicionar dados básicos da campanha mesmo sem insights
-				basicCampaignData := &models.MetaAdsData{
-					ID:                campaignID,
-					Nome:              campaignName,
-					CTR:               0,
-					CAC:               0,
-					InvestimentoTotal: 0,
-					NumeroVendas:      0,
-				}
-				consolidated = append(consolidated, basicCampaignData)
-				fmt.Printf("Adicionados dados básicos da campanha %s (sem insights)\n", campaignID)
-				continue
+
+			result.insights, result.err = processInsightsData(resultRows(responses[i]), result.id, result.nome, opts.actionTypes())
+		}
+	})
+
+	// Montar o resultado na ordem das contas, cada conta seguida das suas campanhas
+	consolidated := []*models.MetaAdsData{}
+	itemErrors := []models.MetaAdsItemError{}
+	next := 0
+	for i, result := range accountResults {
+		if result.insights != nil {
+			if result.insights.Moeda == "" {
+				result.insights.Moeda = result.currency
 			}
+			consolidated = append(consolidated, result.insights)
+		}
+		itemErrors = append(itemErrors, result.errs...)
 
-			consolidated = append(consolidated, insights)
-			fmt.Printf("Adicionados insights da campanha %s\n", campaignID)
+		for ; next < len(campaignResults) && campaignResults[next].account == i; next++ {
+			campaign := campaignResults[next]
+			if campaign.err != nil {
+				itemErrors = append(itemErrors, metaItemError("campanha", campaign.id, campaign.nome, campaign.err))
+				continue
+			}
+			if campaign.insights.Moeda == "" {
+				campaign.insights.Moeda = result.currency
+			}
+			consolidated = append(consolidated, campaign.insights)
 		}
 	}
 
-	// Se não encontrou nenhum dado, retornar lista vazia
-	if len(consolidated) == 0 {
-		fmt.Println("Nenhum dado encontrado.")
+	fmt.Printf("Dados consolidados: %d itens, %d erros\n", len(consolidated), len(itemErrors))
+
+	return consolidated, itemErrors, nil
+}
+
+// forEach executa fn para cada índice de 0 a n-1 com no máximo s.Concurrency execuções simultâneas
+func (s *MetaAdsService) forEach(n int, fn func(i int)) {
+	workers := s.Concurrency
+	if workers <= 0 {
+		workers = DefaultMetaConcurrency
 	}
+	forEachLimit(n, workers, fn)
+}
 
-	fmt.Printf("Total de %d itens consolidados retornados\n", len(consolidated))
-	return consolidated, nil
+// metaItemError descreve a falha de uma conta ou campanha nos dados consolidados
+func metaItemError(tipo string, id string, nome string, err error) models.MetaAdsItemError {
+	return models.MetaAdsItemError{
+		Tipo:  tipo,
+		ID:    id,
+		Nome:  nome,
+		Error: extractErrorInfoFromMain(err),
+	}
 }
@@ -0,0 +1,379 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"poc-integracoes-onm/models"
+
+	fb "github.com/huandu/facebook/v2"
+)
+
+// Valores padrão da sincronização de públicos personalizados
+const (
+	DefaultAudienceWindowDays = 7     // Janela padrão dos públicos de carrinho abandonado
+	DefaultPhoneCountryCode   = "55"  // DDI adicionado aos telefones sem código do país
+	metaAudienceBatchSize     = 10000 // Máximo de usuários por chamada ao endpoint /users
+)
+
+// ErrInvalidMetaAudience indica que a definição do público é inválida
+var ErrInvalidMetaAudience = errors.New("público inválido")
+
+// ErrMetaAudienceNotFound indica que o público não foi criado por este servidor
+var ErrMetaAudienceNotFound = errors.New("público não encontrado")
+
+// metaAudienceSchema define a ordem dos identificadores enviados em cada linha
+var metaAudienceSchema = []string{"EMAIL", "PHONE"}
+
+// metaAudience guarda a definição do público, o token usado nas sincronizações e os membros já enviados ao Meta
+type metaAudience struct {
+	audience models.MetaAudience
+	token    string
+	members  map[string][]string // Linha enviada ao Meta (hashes de e-mail e telefone) por hash do e-mail
+}
+
+// MetaAudienceStore mantém em memória os públicos personalizados sincronizados com os eventos de checkout
+type MetaAudienceStore struct {
+	mu        sync.Mutex
+	syncMu    sync.Mutex // Evita duas sincronizações simultâneas (agendada e manual)
+	audiences map[string]*metaAudience
+}
+
+// NewMetaAudienceStore cria um armazenamento vazio de públicos personalizados
+func NewMetaAudienceStore() *MetaAudienceStore {
+	return &MetaAudienceStore{
+		audiences: make(map[string]*metaAudience),
+	}
+}
+
+// List retorna os públicos cadastrados, ordenados pelo nome
+func (s *MetaAudienceStore) List() []models.MetaAudience {
+	s.mu.Lock()
+	audiences := make([]models.MetaAudience, 0, len(s.audiences))
+	for _, stored := range s.audiences {
+		audiences = append(audiences, stored.audience)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(audiences, func(i, j int) bool {
+		return audiences[i].Nome < audiences[j].Nome
+	})
+	return audiences
+}
+
+// ids retorna os IDs de todos os públicos cadastrados
+func (s *MetaAudienceStore) ids() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, 0, len(s.audiences))
+	for id := range s.audiences {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// CreateCustomAudience cria o público personalizado na conta e o registra para sincronização
+func (s *MetaAdsService) CreateCustomAudience(store *MetaAudienceStore, request models.MetaAudienceRequest) (*models.MetaAudience, error) {
+	if request.Token == "" {
+		return nil, errors.New("token não fornecido")
+	}
+	if request.Tipo != models.PublicoCompradores && request.Tipo != models.PublicoCarrinhoAbandonado {
+		return nil, fmt.Errorf("%w: tipo deve ser %s ou %s", ErrInvalidMetaAudience, models.PublicoCompradores, models.PublicoCarrinhoAbandonado)
+	}
+	if request.JanelaDias < 0 {
+		return nil, fmt.Errorf("%w: janela_dias não pode ser negativa", ErrInvalidMetaAudience)
+	}
+
+	audience := models.MetaAudience{
+		ContaID:   strings.TrimPrefix(request.ContaID, "act_"),
+		Nome:      request.Nome,
+		Tipo:      request.Tipo,
+		ProdutoID: request.ProdutoID,
+	}
+	if audience.Tipo == models.PublicoCarrinhoAbandonado {
+		audience.JanelaDias = request.JanelaDias
+		if audience.JanelaDias == 0 {
+			audience.JanelaDias = DefaultAudienceWindowDays
+		}
+	}
+
+	res, err := s.session(request.Token).Post("/act_"+audience.ContaID+"/customaudiences", fb.Params{
+		"name":                 audience.Nome,
+		"subtype":              "CUSTOM",
+		"description":          audienceDescription(audience),
+		"customer_file_source": "USER_PROVIDED_ONLY",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar o público personalizado: %w", err)
+	}
+
+	audience.ID, _ = res["id"].(string)
+	if audience.ID == "" {
+		return nil, errors.New("o Meta não retornou o ID do público criado")
+	}
+
+	store.mu.Lock()
+	store.audiences[audience.ID] = &metaAudience{
+		audience: audience,
+		token:    request.Token,
+		members:  make(map[string][]string),
+	}
+	store.mu.Unlock()
+
+	return &audience, nil
+}
+
+// SyncCustomAudience calcula os membros atuais do público a partir dos eventos e envia ao Meta
+// apenas as diferenças: novos clientes são adicionados e quem deixou de se qualificar
+// (por exemplo, após um reembolso) é removido
+func (s *MetaAdsService) SyncCustomAudience(store *MetaAudienceStore, events *EventStore, audienceID string) (*models.MetaAudienceSyncResult, error) {
+	store.syncMu.Lock()
+	defer store.syncMu.Unlock()
+
+	store.mu.Lock()
+	stored, ok := store.audiences[audienceID]
+	var audience models.MetaAudience
+	var token string
+	synced := make(map[string][]string)
+	if ok {
+		audience, token = stored.audience, stored.token
+		for key, row := range stored.members {
+			synced[key] = row
+		}
+	}
+	store.mu.Unlock()
+
+	if !ok {
+		return nil, ErrMetaAudienceNotFound
+	}
+
+	now := time.Now()
+	desired := audienceMembers(audience, events, now)
+
+	var toAdd, toRemove [][]string
+	for key, row := range desired {
+		if _, ok := synced[key]; !ok {
+			toAdd = append(toAdd, row)
+		}
+	}
+	for key, row := range synced {
+		if _, ok := desired[key]; !ok {
+			toRemove = append(toRemove, row)
+		}
+	}
+
+	session := s.session(token)
+	err := s.sendAudienceUsers(session, "POST", audienceID, toAdd)
+	if err == nil {
+		err = s.sendAudienceUsers(session, "DELETE", audienceID, toRemove)
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	stored.audience.UltimaSincronizacao = &now
+	if err != nil {
+		stored.audience.UltimoErro = err.Error()
+		return nil, fmt.Errorf("erro ao sincronizar o público %s: %w", audienceID, err)
+	}
+	stored.audience.UltimoErro = ""
+	stored.members = desired
+	stored.audience.Membros = len(desired)
+
+	return &models.MetaAudienceSyncResult{
+		PublicoID:   audienceID,
+		Adicionados: len(toAdd),
+		Removidos:   len(toRemove),
+		Membros:     len(desired),
+		DataHora:    now,
+	}, nil
+}
+
+// SyncAllCustomAudiences sincroniza todos os públicos cadastrados, registrando as falhas no log
+func (s *MetaAdsService) SyncAllCustomAudiences(store *MetaAudienceStore, events *EventStore) {
+	for _, id := range store.ids() {
+		result, err := s.SyncCustomAudience(store, events, id)
+		if err != nil {
+			log.Printf("Erro na sincronização agendada do público %s: %v", id, err)
+			continue
+		}
+		log.Printf("Público %s sincronizado: %d adicionados, %d removidos, %d membros", id, result.Adicionados, result.Removidos, result.Membros)
+	}
+}
+
+// StartAudienceSync sincroniza todos os públicos periodicamente até o canal stop ser fechado
+func (s *MetaAdsService) StartAudienceSync(store *MetaAudienceStore, events *EventStore, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.SyncAllCustomAudiences(store, events)
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// sendAudienceUsers adiciona (POST) ou remove (DELETE) usuários do público em lotes
+func (s *MetaAdsService) sendAudienceUsers(session *fb.Session, method string, audienceID string, rows [][]string) error {
+	for start := 0; start < len(rows); start += metaAudienceBatchSize {
+		end := start + metaAudienceBatchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		payload, err := json.Marshal(map[string]interface{}{
+			"schema": metaAudienceSchema,
+			"data":   rows[start:end],
+		})
+		if err != nil {
+			return err
+		}
+
+		params := fb.Params{"payload": string(payload)}
+		if method == "DELETE" {
+			_, err = session.Delete("/"+audienceID+"/users", params)
+		} else {
+			_, err = session.Post("/"+audienceID+"/users", params)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// audienceMembers calcula os clientes que fazem parte do público, indexados pelo hash do e-mail
+func audienceMembers(audience models.MetaAudience, events *EventStore, now time.Time) map[string][]string {
+	members := make(map[string][]string)
+	since := now.AddDate(0, 0, -audience.JanelaDias)
+
+	for email, history := range events.GroupByCustomer() {
+		refunded := make(map[string]bool)
+		for _, event := range history {
+			if event.Tipo == models.EventoReembolso {
+				refunded[event.Plataforma+"|"+event.ID] = true
+			}
+		}
+
+		var phone string
+		var lastPurchase, lastAbandon time.Time
+		for _, event := range history {
+			if event.Telefone != "" {
+				phone = event.Telefone
+			}
+			if audience.ProdutoID != "" && event.ProdutoID != audience.ProdutoID {
+				continue
+			}
+
+			switch event.Tipo {
+			case models.EventoCompra:
+				if !refunded[event.Plataforma+"|"+event.ID] {
+					lastPurchase = event.DataEvento
+				}
+			case models.EventoCarrinhoAbandonado:
+				lastAbandon = event.DataEvento
+			}
+		}
+
+		var member bool
+		switch audience.Tipo {
+		case models.PublicoCompradores:
+			member = !lastPurchase.IsZero()
+		case models.PublicoCarrinhoAbandonado:
+			// Abandonou dentro da janela e não comprou depois do abandono
+			member = !lastAbandon.IsZero() && !lastAbandon.Before(since) && lastPurchase.Before(lastAbandon)
+		}
+
+		if member {
+			emailHash := HashAudienceEmail(email)
+			members[emailHash] = []string{emailHash, HashAudiencePhone(phone)}
+		}
+	}
+
+	return members
+}
+
+// audienceDescription descreve no Meta a origem dos membros do público
+func audienceDescription(audience models.MetaAudience) string {
+	description := "Compradores"
+	if audience.Tipo == models.PublicoCarrinhoAbandonado {
+		description = fmt.Sprintf("Carrinhos abandonados nos últimos %d dias", audience.JanelaDias)
+	}
+	if audience.ProdutoID != "" {
+		description += " do produto " + audience.ProdutoID
+	}
+	return description + " (sincronizado a partir dos webhooks de checkout)"
+}
+
+// HashAudienceEmail normaliza o e-mail (sem espaços, em minúsculas) e retorna seu SHA-256, como exigido pelo Meta
+func HashAudienceEmail(email string) string {
+	return sha256Hex(NormalizeEmail(email))
+}
+
+// HashAudiencePhone normaliza o telefone (apenas dígitos, com código do país) e retorna seu SHA-256.
+// Telefones com + ou 00 são tratados como internacionais (E.164); os demais recebem o DDI 55 apenas se tiverem
+// o formato de um número brasileiro sem DDI, e caso contrário são considerados já com o código do país.
+// Retorna vazio se não houver telefone.
+func HashAudiencePhone(phone string) string {
+	phone = strings.TrimSpace(phone)
+	international := strings.HasPrefix(phone, "+")
+
+	var digits strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+
+	normalized := digits.String()
+	// 00 é o prefixo de discagem internacional, seguido do código do país
+	if !international && strings.HasPrefix(normalized, "00") {
+		normalized = normalized[2:]
+		international = true
+	}
+	// Zeros à esquerda restantes são o prefixo de discagem nacional (ex: 011 99999-9999)
+	normalized = strings.TrimLeft(normalized, "0")
+	if normalized == "" {
+		return ""
+	}
+	if !international && isBrazilianLocalPhone(normalized) {
+		normalized = DefaultPhoneCountryCode + normalized
+	}
+
+	return sha256Hex(normalized)
+}
+
+// isBrazilianLocalPhone indica se os dígitos têm o formato de um telefone brasileiro sem DDI: DDD (dois dígitos
+// de 1 a 9) seguido de um fixo de 8 dígitos começando com 2 a 5 ou de um celular de 9 dígitos começando com 9.
+// Números com código do país, como os dos EUA (1 + 10 dígitos), não têm esse formato.
+func isBrazilianLocalPhone(digits string) bool {
+	if len(digits) != 10 && len(digits) != 11 {
+		return false
+	}
+	if digits[0] < '1' || digits[1] < '1' {
+		return false
+	}
+	if len(digits) == 11 {
+		return digits[2] == '9'
+	}
+	return digits[2] >= '2' && digits[2] <= '5'
+}
+
+// sha256Hex retorna o SHA-256 do valor em hexadecimal
+func sha256Hex(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
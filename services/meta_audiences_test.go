@@ -0,0 +1,35 @@
+package services
+
+import "testing"
+
+func TestHashAudiencePhone(t *testing.T) {
+	tests := []struct {
+		name  string
+		phone string
+		want  string // Número normalizado antes do SHA-256; vazio quando não há telefone
+	}{
+		{name: "vazio", phone: "", want: ""},
+		{name: "sem dígitos", phone: "n/a", want: ""},
+		{name: "celular brasileiro sem DDI", phone: "(11) 98765-4321", want: "5511987654321"},
+		{name: "fixo brasileiro sem DDI", phone: "21 3456-7890", want: "552134567890"},
+		{name: "brasileiro com prefixo nacional", phone: "011 98765-4321", want: "5511987654321"},
+		{name: "brasileiro com DDI", phone: "55 11 98765-4321", want: "5511987654321"},
+		{name: "brasileiro em E.164", phone: "+55 11 98765-4321", want: "5511987654321"},
+		{name: "EUA com código do país", phone: "1 (212) 555-1234", want: "12125551234"},
+		{name: "EUA em E.164", phone: "+1 212 555 1234", want: "12125551234"},
+		{name: "internacional com 00", phone: "00 44 20 7946 0958", want: "442079460958"},
+		{name: "E.164 com formato de número brasileiro", phone: "+1 1 98765-4321", want: "11987654321"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := ""
+			if tt.want != "" {
+				want = sha256Hex(tt.want)
+			}
+			if got := HashAudiencePhone(tt.phone); got != want {
+				t.Errorf("HashAudiencePhone(%q) = %s, esperado o SHA-256 de %q", tt.phone, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	fb "github.com/huandu/facebook/v2"
+)
+
+// MetaBatchSize é o número máximo de sub-requisições aceitas pela Graph API em um único batch
+const MetaBatchSize = 50
+
+// batchGet executa uma requisição GET para cada caminho, agrupadas em batches de até MetaBatchSize.
+// Retorna um resultado e um erro por caminho, na mesma ordem; a falha de um item não afeta os demais.
+func (s *MetaAdsService) batchGet(session *fb.Session, paths []string, params fb.Params) ([]fb.Result, []error) {
+	results := make([]fb.Result, len(paths))
+	errs := make([]error, len(paths))
+
+	query := url.Values{}
+	for key, value := range params {
+		query.Set(key, fmt.Sprint(value))
+	}
+	encoded := query.Encode()
+
+	for start := 0; start < len(paths); start += MetaBatchSize {
+		end := start + MetaBatchSize
+		if end > len(paths) {
+			end = len(paths)
+		}
+
+		requests := make([]fb.Params, 0, end-start)
+		for _, path := range paths[start:end] {
+			requests = append(requests, fb.Params{
+				"method":       "GET",
+				"relative_url": fb.Version + "/" + strings.TrimPrefix(path, "/") + "?" + encoded,
+			})
+		}
+
+		responses, err := session.BatchApi(requests...)
+		if err != nil {
+			for i := start; i < end; i++ {
+				errs[i] = fmt.Errorf("erro no batch da Graph API: %w", err)
+			}
+			continue
+		}
+
+		for i := start; i < end; i++ {
+			results[i], errs[i] = s.batchItem(responses, i-start, paths[i])
+		}
+	}
+
+	return results, errs
+}
+
+// batchItem interpreta a resposta de uma sub-requisição do batch
+func (s *MetaAdsService) batchItem(responses []fb.Result, index int, path string) (fb.Result, error) {
+	// O Meta devolve null para sub-requisições que não foram executadas (ex: tempo esgotado)
+	if index >= len(responses) || responses[index] == nil {
+		return nil, errors.New("a requisição não foi executada no batch; tente novamente")
+	}
+
+	item, err := responses[index].Batch()
+	if err != nil {
+		return nil, fmt.Errorf("resposta inválida no batch: %w", err)
+	}
+
+	if s.RateLimiter != nil {
+		s.RateLimiter.ObserveHeader(path, item.Header)
+	}
+
+	if err := item.Result.Err(); err != nil {
+		return nil, err
+	}
+	if item.StatusCode != 200 {
+		return nil, fmt.Errorf("a requisição retornou o status HTTP %d", item.StatusCode)
+	}
+
+	return item.Result, nil
+}
+
+// resultRows converte o campo data de uma resposta de listagem em uma lista de resultados
+func resultRows(res fb.Result) []fb.Result {
+	rows := []fb.Result{}
+	data, _ := res["data"].([]interface{})
+	for _, row := range data {
+		if rowMap, ok := row.(map[string]interface{}); ok {
+			rows = append(rows, rowMap)
+		}
+	}
+	return rows
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"poc-integracoes-onm/models"
+
+	fb "github.com/huandu/facebook/v2"
+)
+
+// metaLeadFields são os campos lidos de cada lead na Graph API
+const metaLeadFields = "id,created_time,form_id,ad_id,ad_name,adset_id,campaign_id,campaign_name,platform,field_data"
+
+// MetaPageTokenStore mantém em memória os tokens das páginas usados para ler os leads
+type MetaPageTokenStore struct {
+	mu    sync.RWMutex
+	pages map[string]models.MetaPage
+}
+
+// NewMetaPageTokenStore cria um armazenamento vazio de tokens de página
+func NewMetaPageTokenStore() *MetaPageTokenStore {
+	return &MetaPageTokenStore{
+		pages: make(map[string]models.MetaPage),
+	}
+}
+
+// Set registra ou atualiza o token de uma página
+func (s *MetaPageTokenStore) Set(page models.MetaPage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pages[page.ID] = page
+}
+
+// Token retorna o token da página informada
+func (s *MetaPageTokenStore) Token(pageID string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	page, ok := s.pages[pageID]
+	return page.Token, ok && page.Token != ""
+}
+
+// List retorna as páginas cadastradas, ordenadas pelo nome
+func (s *MetaPageTokenStore) List() []models.MetaPage {
+	s.mu.RLock()
+	pages := make([]models.MetaPage, 0, len(s.pages))
+	for _, page := range s.pages {
+		pages = append(pages, page)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].Nome < pages[j].Nome
+	})
+	return pages
+}
+
+// SyncPageTokens obtém as páginas administradas pelo usuário (/me/accounts) e armazena seus tokens
+func (s *MetaAdsService) SyncPageTokens(store *MetaPageTokenStore, userToken string) ([]models.MetaPage, error) {
+	if userToken == "" {
+		return nil, errors.New("token não fornecido")
+	}
+
+	rows, err := s.Paginator.FetchAll(s.session(userToken), "/me/accounts", fb.Params{
+		"fields": "id,name,access_token",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter as páginas do usuário: %w", err)
+	}
+
+	pages := []models.MetaPage{}
+	for _, row := range rows {
+		page := models.MetaPage{}
+		page.ID, _ = row["id"].(string)
+		page.Nome, _ = row["name"].(string)
+		page.Token, _ = row["access_token"].(string)
+		if page.ID == "" || page.Token == "" {
+			continue
+		}
+
+		store.Set(page)
+		pages = append(pages, page)
+	}
+
+	return pages, nil
+}
+
+// GetLeadEvent lê um lead na Graph API com o token da página e o converte em evento
+func (s *MetaAdsService) GetLeadEvent(store *MetaPageTokenStore, change models.MetaLeadgenValue) (models.CheckoutEvent, error) {
+	if change.LeadgenID == "" {
+		return models.CheckoutEvent{}, errors.New("leadgen_id não informado")
+	}
+
+	pageToken, ok := store.Token(change.PageID)
+	if !ok {
+		return models.CheckoutEvent{}, fmt.Errorf("nenhum token cadastrado para a página %s", change.PageID)
+	}
+
+	res, err := s.session(pageToken).Get("/"+change.LeadgenID, fb.Params{
+		"fields": metaLeadFields,
+	})
+	if err != nil {
+		return models.CheckoutEvent{}, fmt.Errorf("erro ao obter o lead %s: %w", change.LeadgenID, err)
+	}
+
+	// fb.Result é um map; a conversão via JSON aproveita as tags do modelo
+	raw, err := json.Marshal(res)
+	if err != nil {
+		return models.CheckoutEvent{}, fmt.Errorf("erro ao ler o lead %s: %w", change.LeadgenID, err)
+	}
+
+	var lead models.MetaLead
+	if err := json.Unmarshal(raw, &lead); err != nil {
+		return models.CheckoutEvent{}, fmt.Errorf("erro ao ler o lead %s: %w", change.LeadgenID, err)
+	}
+
+	return CheckoutEventFromMetaLead(lead), nil
+}
+
+// ValidateMetaSignature verifica o cabeçalho X-Hub-Signature-256 (sha256=<hmac>) enviado pelo Meta
+func ValidateMetaSignature(body []byte, signature string, appSecret string) bool {
+	if appSecret == "" || !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	fb "github.com/huandu/facebook/v2"
+)
+
+// Valores padrão da paginação das listagens da Graph API
+const (
+	DefaultMetaPageSize = 100
+	DefaultMetaMaxPages = 0 // Sem limite: segue os cursores até a última página
+)
+
+// ErrMetaPageLimit indica que a listagem tem mais páginas do que o limite MaxPages; os itens já lidos
+// não são retornados como se a listagem estivesse completa
+var ErrMetaPageLimit = errors.New("limite de páginas atingido")
+
+// MetaPaginator percorre listagens paginadas da Graph API seguindo os cursores paging.next
+type MetaPaginator struct {
+	PageSize int // Itens por página (parâmetro limit); usa DefaultMetaPageSize se zero
+	MaxPages int // Número máximo de páginas lidas; zero segue até a última página
+}
+
+// NewMetaPaginator cria um paginador com os valores das variáveis de ambiente META_PAGE_SIZE e META_MAX_PAGES, se definidas
+func NewMetaPaginator() MetaPaginator {
+	paginator := MetaPaginator{
+		PageSize: DefaultMetaPageSize,
+		MaxPages: DefaultMetaMaxPages,
+	}
+
+	if value, err := strconv.Atoi(os.Getenv("META_PAGE_SIZE")); err == nil && value > 0 {
+		paginator.PageSize = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("META_MAX_PAGES")); err == nil && value >= 0 {
+		paginator.MaxPages = value
+	}
+
+	return paginator
+}
+
+// FetchAll executa a consulta e retorna os itens de todas as páginas
+func (p MetaPaginator) FetchAll(session *fb.Session, path string, params fb.Params) ([]fb.Result, error) {
+	var rows []fb.Result
+	err := p.Each(session, path, params, func(page []fb.Result) error {
+		rows = append(rows, page...)
+		return nil
+	})
+	return rows, err
+}
+
+// Each executa a consulta e chama fn para cada página, seguindo os cursores até a última página. Se houver
+// mais páginas do que MaxPages, retorna ErrMetaPageLimit depois de ler as MaxPages primeiras
+func (p MetaPaginator) Each(session *fb.Session, path string, params fb.Params, fn func(page []fb.Result) error) error {
+	pageSize := p.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultMetaPageSize
+	}
+
+	query := fb.Params{}
+	for key, value := range params {
+		query[key] = value
+	}
+	query["limit"] = strconv.Itoa(pageSize)
+
+	res, err := session.Get(path, query)
+	if err != nil {
+		return err
+	}
+
+	paging, err := res.Paging(session)
+	if err != nil {
+		return fmt.Errorf("resposta de %s não é uma listagem paginada: %w", path, err)
+	}
+
+	for page := 1; ; page++ {
+		if err := fn(paging.Data()); err != nil {
+			return err
+		}
+
+		if !paging.HasNext() {
+			return nil
+		}
+
+		if p.MaxPages > 0 && page >= p.MaxPages {
+			log.Printf("Limite de %d páginas atingido em %s; itens restantes não foram lidos", p.MaxPages, path)
+			return fmt.Errorf("%w: %s tem mais de %d páginas (aumente META_MAX_PAGES ou use 0 para ler todas)", ErrMetaPageLimit, path, p.MaxPages)
+		}
+
+		if _, err := paging.Next(); err != nil {
+			return fmt.Errorf("erro ao obter a página %d de %s: %w", page+1, path, err)
+		}
+	}
+}
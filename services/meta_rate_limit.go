@@ -0,0 +1,191 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Valores padrão do controle de rate limit da Graph API
+const (
+	DefaultMetaUsageThreshold = 75.0             // Percentual de uso a partir do qual as chamadas são espaçadas
+	DefaultMetaMaxDelay       = 30 * time.Second // Espera máxima entre chamadas quando o uso chega a 100%
+	metaUsageTTL              = 5 * time.Minute  // Leituras mais antigas que isso são descartadas
+)
+
+// metaRateLimiter é compartilhado por todas as instâncias do serviço, pois o limite é da aplicação e das contas
+var metaRateLimiter = NewMetaRateLimiter()
+
+// metaUsage guarda a última leitura de um cabeçalho de uso
+type metaUsage struct {
+	pct    float64   // Maior percentual de uso informado
+	regain time.Time // Momento em que o acesso é liberado, se a cota estiver esgotada
+	seen   time.Time // Momento da leitura
+}
+
+// metaBusinessUseCaseUsage representa cada item do cabeçalho X-Business-Use-Case-Usage
+type metaBusinessUseCaseUsage struct {
+	Type                        string  `json:"type"`
+	CallCount                   float64 `json:"call_count"`
+	TotalTime                   float64 `json:"total_time"`
+	TotalCPUTime                float64 `json:"total_cputime"`
+	EstimatedTimeToRegainAccess int     `json:"estimated_time_to_regain_access"` // Em minutos
+}
+
+// metaAdAccountUsage representa o cabeçalho X-Ad-Account-Usage
+type metaAdAccountUsage struct {
+	AccIDUtilPct      float64 `json:"acc_id_util_pct"`
+	ResetTimeDuration int     `json:"reset_time_duration"` // Em segundos
+}
+
+// MetaRateLimiter lê os cabeçalhos X-Business-Use-Case-Usage e X-Ad-Account-Usage das respostas da Graph API
+// e espaça as chamadas seguintes à medida que o uso se aproxima do limite, evitando o bloqueio
+type MetaRateLimiter struct {
+	Threshold float64
+	MaxDelay  time.Duration
+	Transport http.RoundTripper
+
+	mu    sync.Mutex
+	usage map[string]metaUsage
+}
+
+// NewMetaRateLimiter cria um controle de rate limit com os valores padrão
+func NewMetaRateLimiter() *MetaRateLimiter {
+	return &MetaRateLimiter{
+		Threshold: DefaultMetaUsageThreshold,
+		MaxDelay:  DefaultMetaMaxDelay,
+		Transport: http.DefaultTransport,
+		usage:     make(map[string]metaUsage),
+	}
+}
+
+// Client retorna um cliente HTTP que passa todas as chamadas pelo controle de rate limit
+func (l *MetaRateLimiter) Client() *http.Client {
+	return &http.Client{Transport: l}
+}
+
+// RoundTrip aguarda o tempo necessário antes da chamada e registra o uso informado na resposta
+func (l *MetaRateLimiter) RoundTrip(req *http.Request) (*http.Response, error) {
+	if wait := l.Delay(); wait > 0 {
+		fmt.Printf("Uso da API do Meta próximo do limite; aguardando %s antes da próxima chamada\n", wait.Round(time.Millisecond))
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+
+	transport := l.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err == nil {
+		l.ObserveHeader(req.URL.Path, resp.Header)
+	}
+	return resp, err
+}
+
+// Delay calcula quanto tempo aguardar antes da próxima chamada, de acordo com o maior uso registrado
+func (l *MetaRateLimiter) Delay() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	var wait time.Duration
+	for key, usage := range l.usage {
+		if now.Sub(usage.seen) > metaUsageTTL {
+			delete(l.usage, key)
+			continue
+		}
+
+		if usage.regain.After(now) {
+			if remaining := usage.regain.Sub(now); remaining > wait {
+				wait = remaining
+			}
+			continue
+		}
+
+		if usage.pct < l.Threshold || l.Threshold >= 100 {
+			continue
+		}
+
+		pct := usage.pct
+		if pct > 100 {
+			pct = 100
+		}
+		delay := time.Duration(float64(l.MaxDelay) * (pct - l.Threshold) / (100 - l.Threshold))
+		if delay > wait {
+			wait = delay
+		}
+	}
+
+	return wait
+}
+
+// ObserveHeader registra o uso informado nos cabeçalhos da resposta à chamada do caminho informado
+func (l *MetaRateLimiter) ObserveHeader(path string, header http.Header) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if value := header.Get("X-Business-Use-Case-Usage"); value != "" {
+		var usage map[string][]metaBusinessUseCaseUsage
+		if err := json.Unmarshal([]byte(value), &usage); err == nil {
+			for businessID, items := range usage {
+				for _, item := range items {
+					reading := metaUsage{
+						pct:  maxFloat(item.CallCount, item.TotalTime, item.TotalCPUTime),
+						seen: now,
+					}
+					if item.EstimatedTimeToRegainAccess > 0 {
+						reading.regain = now.Add(time.Duration(item.EstimatedTimeToRegainAccess) * time.Minute)
+					}
+					l.usage["business:"+businessID+":"+item.Type] = reading
+				}
+			}
+		}
+	}
+
+	if value := header.Get("X-Ad-Account-Usage"); value != "" {
+		var usage metaAdAccountUsage
+		if err := json.Unmarshal([]byte(value), &usage); err == nil {
+			reading := metaUsage{
+				pct:  usage.AccIDUtilPct,
+				seen: now,
+			}
+			if usage.AccIDUtilPct >= 100 && usage.ResetTimeDuration > 0 {
+				reading.regain = now.Add(time.Duration(usage.ResetTimeDuration) * time.Second)
+			}
+			l.usage["ad_account:"+metaAccountFromPath(path)] = reading
+		}
+	}
+}
+
+// metaAccountFromPath extrai o act_X do caminho da chamada, se houver
+func metaAccountFromPath(path string) string {
+	for _, part := range strings.Split(path, "/") {
+		if strings.HasPrefix(part, "act_") {
+			return part
+		}
+	}
+	return ""
+}
+
+// maxFloat retorna o maior dos valores informados
+func maxFloat(values ...float64) float64 {
+	var result float64
+	for _, value := range values {
+		if value > result {
+			result = value
+		}
+	}
+	return result
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"poc-integracoes-onm/models"
+
+	fb "github.com/huandu/facebook/v2"
+)
+
+// Estados dos relatórios assíncronos expostos pela API
+const (
+	MetaReportPendente   = "pendente"
+	MetaReportExecutando = "executando"
+	MetaReportConcluido  = "concluido"
+	MetaReportFalhou     = "falhou"
+)
+
+// Níveis aceitos nos relatórios assíncronos
+var metaReportLevels = map[string]bool{
+	"account":  true,
+	"campaign": true,
+	"adset":    true,
+	"ad":       true,
+}
+
+// ErrMetaReportNotFound indica que o relatório não foi enviado por este servidor
+var ErrMetaReportNotFound = errors.New("relatório não encontrado")
+
+// ErrMetaReportNotReady indica que o relatório ainda não foi concluído pelo Meta
+var ErrMetaReportNotReady = errors.New("relatório ainda não foi concluído")
+
+// metaReportJob guarda o estado do relatório e as opções usadas para processar o resultado
+type metaReportJob struct {
+	job  models.MetaReportJob
+	opts MetaInsightsOptions
+}
+
+// MetaReportJobStore mantém em memória os relatórios assíncronos enviados ao Meta
+type MetaReportJobStore struct {
+	mu   sync.RWMutex
+	jobs map[string]*metaReportJob
+}
+
+// NewMetaReportJobStore cria um armazenamento vazio de relatórios assíncronos
+func NewMetaReportJobStore() *MetaReportJobStore {
+	return &MetaReportJobStore{
+		jobs: make(map[string]*metaReportJob),
+	}
+}
+
+// save registra ou atualiza um relatório
+func (s *MetaReportJobStore) save(job models.MetaReportJob, opts MetaInsightsOptions) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = &metaReportJob{job: job, opts: opts}
+}
+
+// get retorna uma cópia do relatório e das opções usadas no envio
+func (s *MetaReportJobStore) get(id string) (models.MetaReportJob, MetaInsightsOptions, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stored, ok := s.jobs[id]
+	if !ok {
+		return models.MetaReportJob{}, MetaInsightsOptions{}, false
+	}
+	return stored.job, stored.opts, true
+}
+
+// SubmitInsightsReport envia um relatório assíncrono de insights (POST /act_X/insights) e o registra no armazenamento
+func (s *MetaAdsService) SubmitInsightsReport(store *MetaReportJobStore, token string, accountID string, level string, opts MetaInsightsOptions) (*models.MetaReportJob, error) {
+	if token == "" {
+		return nil, errors.New("token não fornecido")
+	}
+	if accountID == "" {
+		return nil, errors.New("ID da conta não fornecido")
+	}
+	accountID = strings.TrimPrefix(accountID, "act_")
+
+	if level == "" {
+		level = "campaign"
+	}
+	if err := ValidateMetaReportLevel(level); err != nil {
+		return nil, err
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	session := s.session(token)
+
+	params := insightsParams(level, opts, true)
+	params["fields"] = metaInsightsFields + "," + level + "_id," + level + "_name"
+
+	res, err := session.Post("/act_"+accountID+"/insights", params)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao enviar relatório assíncrono: %w", err)
+	}
+
+	reportRunID, _ := res["report_run_id"].(string)
+	if reportRunID == "" {
+		return nil, errors.New("o Meta não retornou o report_run_id do relatório")
+	}
+
+	job := models.MetaReportJob{
+		ID:       reportRunID,
+		ContaID:  accountID,
+		Nivel:    level,
+		Status:   MetaReportPendente,
+		CriadoEm: time.Now(),
+	}
+	store.save(job, opts)
+
+	return &job, nil
+}
+
+// GetInsightsReportStatus consulta async_status e async_percent_completion do relatório e atualiza o armazenamento
+func (s *MetaAdsService) GetInsightsReportStatus(store *MetaReportJobStore, token string, reportID string) (*models.MetaReportJob, error) {
+	if token == "" {
+		return nil, errors.New("token não fornecido")
+	}
+
+	job, opts, ok := store.get(reportID)
+	if !ok {
+		return nil, ErrMetaReportNotFound
+	}
+
+	// Relatórios finalizados não mudam mais de estado
+	if job.Status == MetaReportConcluido || job.Status == MetaReportFalhou {
+		return &job, nil
+	}
+
+	session := s.session(token)
+
+	res, err := session.Get("/"+reportID, fb.Params{
+		"fields": "id,async_status,async_percent_completion",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar o relatório assíncrono: %w", err)
+	}
+
+	job.StatusMeta, _ = res["async_status"].(string)
+	job.Percentual = int(insightFloat(res, "async_percent_completion"))
+	job.Status = metaReportStatus(job.StatusMeta)
+	if job.Status == MetaReportConcluido {
+		now := time.Now()
+		job.ConcluidoEm = &now
+	}
+	store.save(job, opts)
+
+	return &job, nil
+}
+
+// GetInsightsReportResults baixa as linhas de um relatório concluído, seguindo a paginação
+func (s *MetaAdsService) GetInsightsReportResults(store *MetaReportJobStore, token string, reportID string) (*models.MetaReportJob, []models.MetaAdsSegment, error) {
+	job, err := s.GetInsightsReportStatus(store, token, reportID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Status != MetaReportConcluido {
+		return job, nil, ErrMetaReportNotReady
+	}
+
+	_, opts, _ := store.get(reportID)
+	session := s.session(token)
+
+	rows, err := s.Paginator.FetchAll(session, "/"+reportID+"/insights", fb.Params{})
+	if err != nil {
+		return job, nil, fmt.Errorf("erro ao baixar o resultado do relatório: %w", err)
+	}
+
+	results := []models.MetaAdsSegment{}
+	for _, row := range rows {
+		id, _ := row[job.Nivel+"_id"].(string)
+		nome, _ := row[job.Nivel+"_name"].(string)
+		results = append(results, processInsightsSegments([]fb.Result{row}, id, nome, opts)...)
+	}
+
+	return job, results, nil
+}
+
+// ValidateMetaReportLevel verifica se o nível do relatório é aceito (vazio equivale a campaign)
+func ValidateMetaReportLevel(level string) error {
+	if level != "" && !metaReportLevels[level] {
+		return fmt.Errorf("nível inválido: %s (use account, campaign, adset ou ad)", level)
+	}
+	return nil
+}
+
+// metaReportStatus converte o async_status do Meta para os estados expostos pela API
+func metaReportStatus(asyncStatus string) string {
+	switch asyncStatus {
+	case "Job Completed":
+		return MetaReportConcluido
+	case "Job Failed", "Job Skipped":
+		return MetaReportFalhou
+	case "Job Started", "Job Running":
+		return MetaReportExecutando
+	default:
+		return MetaReportPendente
+	}
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"poc-integracoes-onm/models"
+
+	fb "github.com/huandu/facebook/v2"
+)
+
+// MetaTokenRenewalWindow é a antecedência com que um token passa a ser sinalizado para renovação
+const MetaTokenRenewalWindow = 7 * 24 * time.Hour
+
+// metaDebugToken representa o campo data da resposta do debug_token
+type metaDebugToken struct {
+	AppID               string   `facebook:"app_id"`
+	Type                string   `facebook:"type"`
+	Application         string   `facebook:"application"`
+	UserID              string   `facebook:"user_id"`
+	IsValid             bool     `facebook:"is_valid"`
+	Scopes              []string `facebook:"scopes"`
+	IssuedAt            int64    `facebook:"issued_at"`
+	ExpiresAt           int64    `facebook:"expires_at"`
+	DataAccessExpiresAt int64    `facebook:"data_access_expires_at"`
+	Error               struct {
+		Message string `facebook:"message"`
+	} `facebook:"error"`
+}
+
+// InspectToken consulta o debug_token do Meta e retorna escopos, expiração, aplicativo e usuário do token
+func (s *MetaAdsService) InspectToken(token string) (*models.MetaTokenInfo, error) {
+	if s.Config.AppID == "" || s.Config.AppSecret == "" {
+		return nil, errors.New("configurações incompletas: AppID e AppSecret são obrigatórios")
+	}
+
+	if token == "" {
+		return nil, errors.New("token não fornecido")
+	}
+
+	// O debug_token é chamado com o token do aplicativo (app_id|app_secret)
+	session := s.session("")
+	res, err := session.Get("/debug_token", fb.Params{
+		"input_token":  token,
+		"access_token": s.Config.AppID + "|" + s.Config.AppSecret,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao inspecionar o token: %w", err)
+	}
+
+	var data metaDebugToken
+	if err := res.DecodeField("data", &data); err != nil {
+		return nil, fmt.Errorf("resposta inesperada do debug_token: %w", err)
+	}
+
+	info := &models.MetaTokenInfo{
+		Valido:    data.IsValid,
+		Tipo:      data.Type,
+		AppID:     data.AppID,
+		Aplicacao: data.Application,
+		UserID:    data.UserID,
+		Escopos:   data.Scopes,
+		Erro:      data.Error.Message,
+	}
+	if info.Escopos == nil {
+		info.Escopos = []string{}
+	}
+
+	info.EmitidoEm = unixTime(data.IssuedAt)
+	info.ExpiraEm = unixTime(data.ExpiresAt)
+	info.AcessoDadosExpiraEm = unixTime(data.DataAccessExpiresAt)
+
+	if info.ExpiraEm != nil {
+		days := int(time.Until(*info.ExpiraEm).Hours() / 24)
+		info.DiasRestantes = &days
+	}
+
+	// O token precisa ser renovado quando é inválido ou quando ele (ou o acesso aos dados) expira em breve
+	info.PrecisaRenovar = !info.Valido
+	for _, expiry := range []*time.Time{info.ExpiraEm, info.AcessoDadosExpiraEm} {
+		if expiry != nil && time.Until(*expiry) < MetaTokenRenewalWindow {
+			info.PrecisaRenovar = true
+		}
+	}
+
+	return info, nil
+}
+
+// unixTime converte um timestamp Unix em segundos; zero indica ausência de data
+func unixTime(seconds int64) *time.Time {
+	if seconds <= 0 {
+		return nil
+	}
+	t := time.Unix(seconds, 0)
+	return &t
+}
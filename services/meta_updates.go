@@ -0,0 +1,253 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"poc-integracoes-onm/models"
+
+	fb "github.com/huandu/facebook/v2"
+)
+
+// ErrInvalidMetaUpdate indica que a alteração solicitada é inválida e não foi enviada ao Meta
+var ErrInvalidMetaUpdate = errors.New("alteração inválida")
+
+// Status aceitos nas alterações de campanhas e conjuntos de anúncios
+var metaUpdateStatuses = map[string]bool{
+	"ACTIVE": true,
+	"PAUSED": true,
+}
+
+// Campo de data de término de cada tipo de objeto
+var metaEndTimeFields = map[string]string{
+	"campaign": "stop_time",
+	"adset":    "end_time",
+}
+
+// MetaAuditLog mantém em memória o histórico de alterações feitas no Meta Ads
+type MetaAuditLog struct {
+	mu      sync.RWMutex
+	records []models.MetaAuditRecord
+}
+
+// NewMetaAuditLog cria um histórico de alterações vazio
+func NewMetaAuditLog() *MetaAuditLog {
+	return &MetaAuditLog{}
+}
+
+// Add registra uma alteração, atribuindo seu ID
+func (l *MetaAuditLog) Add(record *models.MetaAuditRecord) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	record.ID = strconv.Itoa(len(l.records) + 1)
+	l.records = append(l.records, *record)
+}
+
+// List retorna as alterações registradas, das mais recentes para as mais antigas
+func (l *MetaAuditLog) List() []models.MetaAuditRecord {
+	l.mu.RLock()
+	records := make([]models.MetaAuditRecord, len(l.records))
+	copy(records, l.records)
+	l.mu.RUnlock()
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].DataHora.After(records[j].DataHora)
+	})
+	return records
+}
+
+// UpdateCampaign altera status, orçamento ou data de término de uma campanha
+func (s *MetaAdsService) UpdateCampaign(audit *MetaAuditLog, campaignID string, request models.MetaUpdateRequest) (*models.MetaAuditRecord, error) {
+	return s.updateObject(audit, "campaign", campaignID, request)
+}
+
+// UpdateAdSet altera status, orçamento ou data de término de um conjunto de anúncios
+func (s *MetaAdsService) UpdateAdSet(audit *MetaAuditLog, adSetID string, request models.MetaUpdateRequest) (*models.MetaAuditRecord, error) {
+	return s.updateObject(audit, "adset", adSetID, request)
+}
+
+// updateObject compara os valores atuais com os solicitados, envia a alteração ao Meta (exceto em dry-run)
+// e registra no histórico quem alterou o quê, inclusive quando o Meta recusa a alteração
+func (s *MetaAdsService) updateObject(audit *MetaAuditLog, objectType string, objectID string, request models.MetaUpdateRequest) (*models.MetaAuditRecord, error) {
+	if request.Token == "" {
+		return nil, errors.New("token não fornecido")
+	}
+	if objectID == "" {
+		return nil, fmt.Errorf("%w: ID do objeto não fornecido", ErrInvalidMetaUpdate)
+	}
+
+	endTimeField := metaEndTimeFields[objectType]
+	params, err := metaUpdateParams(request, endTimeField)
+	if err != nil {
+		return nil, err
+	}
+
+	session := s.session(request.Token)
+
+	// Identificar o usuário dono do token para o registro de auditoria
+	me, err := session.Get("/me", fb.Params{"fields": "id,name"})
+	if err != nil {
+		return nil, fmt.Errorf("token inválido ou não autorizado: %w", err)
+	}
+
+	current, err := session.Get("/"+objectID, fb.Params{
+		"fields": "name,account_id,status,daily_budget,lifetime_budget," + endTimeField,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter o objeto %s: %w", objectID, err)
+	}
+
+	// Orçamentos são enviados na menor unidade da moeda da conta, que depende da moeda (ex: JPY não tem centavos)
+	currency := ""
+	if metaHasBudget(params) {
+		accountID, _ := current["account_id"].(string)
+		if currency, err = s.accountCurrency(session, accountID); err != nil {
+			return nil, err
+		}
+		metaBudgetParams(params, currency)
+	}
+
+	record := &models.MetaAuditRecord{
+		DataHora:   time.Now(),
+		Objeto:     objectType,
+		ObjetoID:   objectID,
+		Alteracoes: metaChanges(current, params, currency),
+		DryRun:     request.DryRun,
+	}
+	record.ObjetoNome, _ = current["name"].(string)
+	record.UsuarioID, _ = me["id"].(string)
+	record.UsuarioNome, _ = me["name"].(string)
+
+	if !request.DryRun {
+		if _, err := session.Post("/"+objectID, params); err != nil {
+			record.Erro = err.Error()
+			audit.Add(record)
+			return record, fmt.Errorf("erro ao alterar o objeto %s: %w", objectID, err)
+		}
+	}
+
+	record.Sucesso = true
+	audit.Add(record)
+	return record, nil
+}
+
+// metaUpdateParams valida a solicitação e monta os parâmetros enviados ao Meta; os orçamentos ficam em unidades
+// monetárias até que metaBudgetParams os converta com a moeda da conta
+func metaUpdateParams(request models.MetaUpdateRequest, endTimeField string) (fb.Params, error) {
+	params := fb.Params{}
+
+	if request.Status != "" {
+		status := strings.ToUpper(request.Status)
+		if !metaUpdateStatuses[status] {
+			return nil, fmt.Errorf("%w: status deve ser ACTIVE ou PAUSED", ErrInvalidMetaUpdate)
+		}
+		params["status"] = status
+	}
+
+	if request.OrcamentoDiario != nil && request.OrcamentoTotal != nil {
+		return nil, fmt.Errorf("%w: informe orcamento_diario ou orcamento_total, não ambos", ErrInvalidMetaUpdate)
+	}
+	if request.OrcamentoDiario != nil {
+		if *request.OrcamentoDiario <= 0 {
+			return nil, fmt.Errorf("%w: orcamento_diario deve ser maior que zero", ErrInvalidMetaUpdate)
+		}
+		// Convertido para a menor unidade da moeda da conta por metaBudgetParams
+		params["daily_budget"] = *request.OrcamentoDiario
+	}
+	if request.OrcamentoTotal != nil {
+		if *request.OrcamentoTotal <= 0 {
+			return nil, fmt.Errorf("%w: orcamento_total deve ser maior que zero", ErrInvalidMetaUpdate)
+		}
+		params["lifetime_budget"] = *request.OrcamentoTotal
+	}
+
+	if request.DataFim != "" {
+		endTime, err := parseMetaEndTime(request.DataFim)
+		if err != nil {
+			return nil, err
+		}
+		params[endTimeField] = endTime.Format("2006-01-02T15:04:05-0700")
+	}
+
+	if len(params) == 0 {
+		return nil, fmt.Errorf("%w: nenhuma alteração informada (status, orcamento_diario, orcamento_total ou data_fim)", ErrInvalidMetaUpdate)
+	}
+
+	return params, nil
+}
+
+// metaBudgetFields são os campos de orçamento, enviados e retornados pela API na menor unidade da moeda
+var metaBudgetFields = []string{"daily_budget", "lifetime_budget"}
+
+// metaHasBudget indica se a alteração inclui um orçamento
+func metaHasBudget(params fb.Params) bool {
+	for _, field := range metaBudgetFields {
+		if _, ok := params[field]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// metaBudgetParams converte os orçamentos da alteração de unidades monetárias para a menor unidade da moeda da conta
+func metaBudgetParams(params fb.Params, currency string) {
+	for _, field := range metaBudgetFields {
+		if amount, ok := params[field].(float64); ok {
+			params[field] = strconv.FormatInt(metaMinorUnits(amount, currency), 10)
+		}
+	}
+}
+
+// parseMetaEndTime interpreta a data de término; datas sem horário terminam às 23:59:59 no fuso do servidor
+func parseMetaEndTime(value string) (time.Time, error) {
+	endTime, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		date, dateErr := time.ParseInLocation("2006-01-02", value, time.Local)
+		if dateErr != nil {
+			return time.Time{}, fmt.Errorf("%w: data_fim inválida (use AAAA-MM-DD ou RFC3339): %s", ErrInvalidMetaUpdate, value)
+		}
+		endTime = date.Add(24*time.Hour - time.Second)
+	}
+
+	if !endTime.After(time.Now()) {
+		return time.Time{}, fmt.Errorf("%w: data_fim deve estar no futuro", ErrInvalidMetaUpdate)
+	}
+
+	return endTime, nil
+}
+
+// metaChanges descreve cada campo alterado com o valor atual e o solicitado; currency é a moeda da conta,
+// usada para exibir os orçamentos
+func metaChanges(current fb.Result, params fb.Params, currency string) []models.MetaChange {
+	fields := make([]string, 0, len(params))
+	for field := range params {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	changes := []models.MetaChange{}
+	for _, field := range fields {
+		change := models.MetaChange{
+			Campo: field,
+			Novo:  fmt.Sprint(params[field]),
+		}
+		change.Anterior, _ = current[field].(string)
+
+		// Orçamentos são exibidos na moeda da conta, não em centavos
+		if containsString(metaBudgetFields, field) {
+			change.Novo = strconv.FormatFloat(metaCurrencyAmount(change.Novo, currency), 'f', 2, 64)
+			if change.Anterior != "" {
+				change.Anterior = strconv.FormatFloat(metaCurrencyAmount(change.Anterior, currency), 'f', 2, 64)
+			}
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes
+}